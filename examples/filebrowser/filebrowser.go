@@ -9,10 +9,16 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/greenenergy/greenbubbles/filebrowser"
+	"github.com/greenenergy/greenbubbles/teatree"
 )
 
 func main() {
 	var debug = flag.Bool("d", false, "create debug log")
+	var state = flag.String("s", "", "remember the open folders and cursor in this file")
+	var details = flag.Bool("l", false, "show the size, modification time and mode of each file")
+	var glyphs = flag.String("glyphs", "nerdfont", "symbols to draw the tree with: nerdfont, unicode or ascii")
+	var guides = flag.Bool("guides", false, "draw lines joining each file to its folder")
+	var start = flag.String("f", "", "start with the cursor on this file")
 	flag.Parse()
 
 	if len(flag.Args()) < 1 {
@@ -35,11 +41,22 @@ func main() {
 	}
 
 	dir := flag.Arg(0)
-	m := filebrowser.New(dir)
-	p := tea.NewProgram(m)
+	result := *start
+	m := filebrowser.New(dir).Value(&result).Details(*details)
+	if *state != "" {
+		m.StateFile(*state)
+	}
+	switch *glyphs {
+	case "unicode":
+		m.Tree.Glyphs = teatree.UnicodeGlyphs
+	case "ascii":
+		m.Tree.Glyphs = teatree.ASCIIGlyphs
+	}
+	m.Tree.Guides = *guides
+	p := tea.NewProgram(m, tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	fmt.Println(m.Value())
+	fmt.Println(result)
 }
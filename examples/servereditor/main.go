@@ -51,12 +51,49 @@ func New() *App {
 			Background(lipgloss.Color("#000030"))
 	}
 
-	additem := teatree.NewItem("[Add Server]", false, nil, nil, addServerLabelStyle, nil, nil, nil, nil)
-	additem.SetSelectFunc(func(ti *teatree.TreeItem) {
-		log.Println("at SelectFunc - should add a new child")
-		app.ItemEditor.Tree.AddChildren(teatree.NewItem("<unnamed>", false, nil, nil, nil, nil, nil, nil, NewServerDefinition()))
-	})
-	app.ItemEditor.Tree.AddChildren(additem)
+	app.additem = teatree.NewItem("[Add Server]", false, nil, nil, addServerLabelStyle, nil, nil, nil, nil)
+	app.ItemEditor.Tree.AddChildren(app.additem)
+	app.ItemEditor.Tree.Columns = []teatree.Column{
+		{Title: "Host", Flex: 1, Cell: func(ti *teatree.TreeItem) string {
+			if sd, ok := ti.Data.(*ServerDefinition); ok {
+				return sd.Host
+			}
+			return ""
+		}},
+		{Title: "Port", Width: 5, Align: lipgloss.Right, Cell: func(ti *teatree.TreeItem) string {
+			if sd, ok := ti.Data.(*ServerDefinition); ok && sd.CmdPort != 0 {
+				return strconv.Itoa(sd.CmdPort)
+			}
+			return ""
+		}},
+	}
+
+	// Renaming a server is done in place, rather than through the whole form
+	app.ItemEditor.Tree.Rename = func(ti *teatree.TreeItem, name string) error {
+		sd, ok := ti.Data.(*ServerDefinition)
+		if !ok {
+			return errors.New("only servers can be renamed")
+		}
+		if name == "" {
+			return errors.New("name can't be empty")
+		}
+		// Servers can be moved into groups, so look through the whole tree
+		taken := app.ItemEditor.Tree.FindFunc(func(other *teatree.TreeItem) bool {
+			_, isServer := other.Data.(*ServerDefinition)
+			return isServer && other != ti && other.Name == name
+		})
+		if taken != nil {
+			return fmt.Errorf("there is already a server called %q", name)
+		}
+		sd.Name = name
+		return nil
+	}
+
+	// Servers can be reordered and grouped with x, p and P, but [Add Server] stays where it is
+	app.ItemEditor.Tree.AllowMoves = true
+	app.ItemEditor.Tree.CanDrop = func(item, parent *teatree.TreeItem) bool {
+		return item != app.additem && parent != app.additem && app.ItemEditor.Tree.ActiveItem != app.additem
+	}
 
 	serverDefs := [][2]string{
 		{"dev", "localhost"},
@@ -88,11 +125,16 @@ func New() *App {
 		}
 	}
 
+	// Mistakes can be reverted with u, and made again with ctrl+r. This is turned on once
+	// the starting servers are in, so that they can't be undone.
+	app.ItemEditor.Tree.UndoLimit = teatree.DefaultUndoLimit
+
 	return &app
 }
 
 type App struct {
 	ItemEditor  *itemeditor.ItemCollectionEditor
+	additem     *teatree.TreeItem
 	Width       int
 	Height      int
 	quitting    bool
@@ -101,7 +143,7 @@ type App struct {
 }
 
 func (a *App) Init() tea.Cmd {
-	return nil
+	return a.ItemEditor.Init()
 }
 
 // func (a *App) EditServerDefinition(sd *ServerDefinition) error {
@@ -213,8 +255,25 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.ClearScreen
 		}
 
+	case teatree.SelectedMsg:
+		if tmsg.Item == a.additem {
+			log.Println("add server selected - adding a new child")
+			a.ItemEditor.Tree.AddChildren(teatree.NewItem("<unnamed>", false, nil, nil, nil, nil, nil, nil, NewServerDefinition()))
+		}
+
+	case teatree.UndoneMsg:
+		log.Println("undone:", tmsg.Edit.Description)
+		syncServerNames(tmsg.Edit.Items)
+
+	case teatree.RedoneMsg:
+		log.Println("redone:", tmsg.Edit.Description)
+		syncServerNames(tmsg.Edit.Items)
+
 	case tea.KeyMsg:
 		log.Println("keymsg:", tmsg.String())
+		if a.ItemEditor.Tree.Typing() {
+			break
+		}
 		switch tmsg.String() {
 		case " ":
 			a.popup = !a.popup
@@ -230,6 +289,16 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// syncServerNames copies the names of the items back to their server definitions, after an
+// undone or redone rename has changed them.
+func syncServerNames(items []*teatree.TreeItem) {
+	for _, ti := range items {
+		if sd, ok := ti.Data.(*ServerDefinition); ok {
+			sd.Name = ti.Name
+		}
+	}
+}
+
 func main() {
 	var debug = flag.Bool("d", false, "create debug log")
 	flag.Parse()
@@ -250,7 +319,7 @@ func main() {
 	}
 
 	m := New()
-	p := tea.NewProgram(m)
+	p := tea.NewProgram(m, tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -1,9 +1,12 @@
 package filebrowser
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/fs"
 	"log"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -22,11 +25,30 @@ const GoGopherDev = "\ue626"
 const GoGopher = "\ue724"
 const GoTitle = "\U000F07D3"
 
+// KeyMap holds the bindings the browser handles itself, on top of those of its tree.
+type KeyMap struct {
+	Choose  key.Binding
+	Refresh key.Binding
+	Quit    key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Choose:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "choose")),
+		Refresh: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		Quit:    key.NewBinding(key.WithKeys("ctrl+c", "q"), key.WithHelp("q", "quit")),
+	}
+}
+
 type FileBrowserModel struct {
 	dir      string
 	result   *string
 	Tree     *teatree.Tree
-	info     func()
+	KeyMap   KeyMap
+	inForm   bool   // Set once the browser has been added to a huh.Form
+	revealed bool   // The starting value has been shown
+	restored bool   // The saved state has been applied
+	state    string // File the open folders and cursor are kept in between runs
 	quitting bool
 	err      error
 }
@@ -36,12 +58,75 @@ func (fbm *FileBrowserModel) Value(value *string) *FileBrowserModel {
 	return fbm
 }
 
+// Details turns the size, modification time and mode columns on or off.
+func (fbm *FileBrowserModel) Details(show bool) *FileBrowserModel {
+	fbm.Tree.Columns = nil
+	if show {
+		fbm.Tree.Columns = []teatree.Column{
+			{Title: "Size", Width: 6, Align: lipgloss.Right, Cell: SizeCell},
+			{Title: "Modified", Width: 16, Cell: ModTimeCell},
+			{Title: "Mode", Width: 10, Cell: ModeCell},
+		}
+	}
+	return fbm
+}
+
+// StateFile sets a file to remember the open folders and the cursor in. The state is restored
+// by Init, and saved when the browser quits or loses focus.
+func (fbm *FileBrowserModel) StateFile(name string) *FileBrowserModel {
+	fbm.state = name
+	return fbm
+}
+
+// saveState writes the tree's state to the state file, if there is one.
+func (fm *FileBrowserModel) saveState() {
+	if fm.state == "" {
+		return
+	}
+	if err := fm.Tree.State().Save(fm.state); err != nil {
+		log.Println("error saving the browser state:", err.Error())
+	}
+}
+
 func (fbm *FileBrowserModel) Blur() tea.Cmd {
+	fbm.saveState()
 	return fbm.Tree.Blur()
 }
 
+// Focus is called by a form when the browser gains focus. Forms don't call Init, so this
+// is where the browser first restores its state and opens on its value.
 func (fbm *FileBrowserModel) Focus() tea.Cmd {
-	return fbm.Tree.Focus()
+	return tea.Batch(fbm.Tree.Focus(), fbm.restoreState(), fbm.revealValue())
+}
+
+// restoreState applies the saved state, if there is one, the first time it is called.
+func (fm *FileBrowserModel) restoreState() tea.Cmd {
+	if fm.restored || fm.state == "" {
+		return nil
+	}
+	fm.restored = true
+	state, err := teatree.LoadState(fm.state)
+	if err != nil {
+		// There won't be a state file the first time through
+		log.Println("not restoring the browser state:", err.Error())
+		return nil
+	}
+	return fm.Tree.ApplyState(state)
+}
+
+// revealValue opens the folders down to the file named by the browser's value, and puts the
+// cursor on it, the first time it is called.
+func (fm *FileBrowserModel) revealValue() tea.Cmd {
+	if fm.revealed || fm.result == nil || *fm.result == "" {
+		return nil
+	}
+	fm.revealed = true
+	rel, err := filepath.Rel(fm.dir, *fm.result)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		// Not below the folder being browsed
+		return nil
+	}
+	return fm.Tree.RevealPath(strings.Split(filepath.ToSlash(rel), "/"))
 }
 
 func (fbm *FileBrowserModel) Error() error {
@@ -49,7 +134,21 @@ func (fbm *FileBrowserModel) Error() error {
 }
 
 func (fbm *FileBrowserModel) Run() error {
-	return fbm.Tree.Run()
+	err := fbm.Tree.Run()
+	if err == nil && fbm.result != nil && fbm.Tree.ActiveItem != nil {
+		*fbm.result = fbm.selectedPath()
+	}
+	return err
+}
+
+// selectedPath returns the full path of the active item.
+func (fm *FileBrowserModel) selectedPath() string {
+	// TODO: If you select something in your current directory ".", then the file will be named
+	// ".whatever" instead of "./whatever". For some reason the slash is not imserted between
+	// the value of fm.dir and the first actual path value.
+	fullList := append([]string{fm.dir}, fm.Tree.ActiveItem.GetPath()...)
+	log.Println("returning:", fullList)
+	return path.Join(fullList...)
 }
 
 // Skip returns whether this input should be skipped or not.
@@ -58,7 +157,23 @@ func (fbm *FileBrowserModel) Skip() bool {
 }
 
 func (fbm *FileBrowserModel) KeyBinds() []key.Binding {
-	return fbm.Tree.KeyBinds()
+	return append(fbm.Tree.KeyBinds(), fbm.KeyMap.Refresh)
+}
+
+// ShortHelp returns the bindings for the one line help, for help.KeyMap.
+func (fbm *FileBrowserModel) ShortHelp() []key.Binding {
+	if fbm.Tree.Typing() {
+		return fbm.Tree.ShortHelp()
+	}
+	k := fbm.Tree.KeyMap
+	return []key.Binding{k.Up, k.Down, k.Open, k.Back, fbm.KeyMap.Choose, fbm.KeyMap.Refresh, fbm.KeyMap.Quit, k.Help}
+}
+
+// FullHelp returns every binding, grouped into columns, for help.KeyMap. The browser's own
+// bindings are listed ahead of the tree's.
+func (fbm *FileBrowserModel) FullHelp() [][]key.Binding {
+	k := fbm.KeyMap
+	return append([][]key.Binding{{k.Choose, k.Refresh, k.Quit}}, fbm.Tree.FullHelp()...)
 }
 
 // GetValue returns the field's value.
@@ -85,9 +200,6 @@ func (fbm *FileBrowserModel) WithWidth(width int) huh.Field {
 
 // WithPosition sets the position of the input field.
 func (fbm *FileBrowserModel) WithPosition(p huh.FieldPosition) huh.Field {
-	//i.keymap.Prev.SetEnabled(!p.IsFirst())
-	//i.keymap.Next.SetEnabled(!p.IsLast())
-	//i.keymap.Submit.SetEnabled(p.IsLast())
 	fbm.Tree.WithPosition(p)
 	return fbm
 }
@@ -104,15 +216,19 @@ func (fbm *FileBrowserModel) WithAccessible(accessible bool) huh.Field {
 	return fbm
 }
 
-// WithKeyMap sets the keymap on an input field.
+// WithKeyMap sets the keymap on an input field. Forms call this on each of their fields, so
+// it also tells the browser to leave the form running when a file is chosen.
 func (fbm *FileBrowserModel) WithKeyMap(k *huh.KeyMap) huh.Field {
 	fbm.Tree.WithKeyMap(k)
-	//t.textinput.KeyMap.AcceptSuggestion = i.keymap.AcceptSuggestion
+	fbm.inForm = true
+	// The form shows its own help
+	fbm.Tree.ShowHelp = false
 	return fbm
 }
 
+// Init restores the saved state, if there is one, and then opens the browser on its value.
 func (fm *FileBrowserModel) Init() tea.Cmd {
-	return nil
+	return tea.Batch(fm.Tree.Init(), fm.restoreState(), fm.revealValue())
 }
 
 func (fm *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -126,44 +242,53 @@ func (fm *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	*/
 
 	case tea.KeyMsg:
-		switch tmsg.String() {
-		case "enter":
-			// TODO: If you select something in your current directory ".", then the file will be named
-			// ".whatever" instead of "./whatever". For some reason the slash is not imserted between
-			// the value of fm.dir and the first actual path value.
-			fullList := append([]string{fm.dir}, fm.Tree.ActiveItem.GetPath()...)
-			log.Println("returning:", fullList)
-
-			res := path.Join(fullList...)
+		if fm.Tree.Typing() {
+			break
+		}
+		switch {
+		case key.Matches(tmsg, fm.KeyMap.Choose):
+			if !fm.Tree.ActiveShown() {
+				// A search has hidden the cursor
+				return fm, nil
+			}
+			res := fm.selectedPath()
 			if fm.result != nil {
 				*fm.result = res
 			}
 
 			log.Println("result:", fm.result)
+			if fm.inForm {
+				// Let the tree tell the form to move on to the next field
+				break
+			}
+			fm.saveState()
 			fm.quitting = true
 			return fm, tea.Quit
 
-		case "r": // Refresh - it will cause the parent of the currently selected item to delete all children and re-fetch them.
-			parent := fm.Tree.ActiveItem.GetParent()
-			parent.Refresh()
-			if _, ok := parent.(*teatree.Tree); ok {
-				// If we're already at the top level, it means a refresh of the root tree
-				fm.Tree.ActiveItem = fm.Tree.Items[0]
-			} else {
-				// If we're not at the top, the simplest thing is to just activate the parent of the
-				// current item and close it for re-opening
-				fm.Tree.ActiveItem = parent.(*teatree.TreeItem)
+		case key.Matches(tmsg, fm.KeyMap.Refresh): // Refresh - it will cause the parent of the currently selected item to delete all children and re-fetch them.
+			if fm.Tree.ActiveItem == nil {
+				break
+			}
+			switch parent := fm.Tree.ActiveItem.GetParent().(type) {
+			case *teatree.Tree:
+				// At the top level, re-read the root folder. The cursor stays on the same
+				// name if it is still there.
+				items, err := fm.readDir(context.Background(), fm.dir)
+				if err != nil {
+					fm.err = err
+					break
+				}
+				parent.ReplaceChildren(items...)
+			case *teatree.TreeItem:
+				// Below the top, close the parent so its contents are re-read when it is
+				// opened again. The cursor moves up to it.
+				parent.Refresh()
 			}
 
-		case "ctrl+c", "q":
+		case key.Matches(tmsg, fm.KeyMap.Quit):
+			fm.saveState()
 			fm.quitting = true
 			return fm, tea.Quit
-
-		case "?":
-			if fm.info != nil {
-				fm.info()
-			}
-			return fm, nil
 		}
 	}
 	_, cmd := fm.Tree.Update(msg)
@@ -183,6 +308,47 @@ func (fm *FileBrowserModel) View() string {
 	return fm.Tree.View()
 }
 
+// fileInfo returns the file info kept on ti, or nil.
+func fileInfo(ti *teatree.TreeItem) fs.FileInfo {
+	info, _ := ti.Data.(fs.FileInfo)
+	return info
+}
+
+// SizeCell shows the size of a file, rounded to the nearest unit. Folders have no size.
+func SizeCell(ti *teatree.TreeItem) string {
+	info := fileInfo(ti)
+	if info == nil || info.IsDir() {
+		return "-"
+	}
+	const units = "KMGT"
+	if info.Size() < 1024 {
+		return fmt.Sprintf("%d", info.Size())
+	}
+	size := float64(info.Size()) / 1024
+	x := 0
+	for size >= 1024 && x < len(units)-1 {
+		size /= 1024
+		x++
+	}
+	return fmt.Sprintf("%.1f%c", size, units[x])
+}
+
+// ModTimeCell shows when a file was last modified.
+func ModTimeCell(ti *teatree.TreeItem) string {
+	if info := fileInfo(ti); info != nil {
+		return info.ModTime().Format("2006-01-02 15:04")
+	}
+	return ""
+}
+
+// ModeCell shows the permission bits of a file.
+func ModeCell(ti *teatree.TreeItem) string {
+	if info := fileInfo(ti); info != nil {
+		return info.Mode().String()
+	}
+	return ""
+}
+
 func TextColor(ti *teatree.TreeItem) lipgloss.Style {
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#FFFFFF")) // white
@@ -215,77 +381,93 @@ func FileColor(ti *teatree.TreeItem) lipgloss.Style {
 		Foreground(lipgloss.Color("#7FFF7F")) // palegreen
 }
 
-func (fm *FileBrowserModel) walk(p string, item teatree.ItemHolder) error {
-	err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			log.Printf("prevent panic by handling failure accessing a path %q: %v\n", path, err)
-			return err
-		}
-		if path == p {
-			// We don't want to render the folder we were sent. This is redundant and confusing for the user.
-			return nil
-		}
+// newItem builds the tree item for the directory entry d, found at path p.
+func (fm *FileBrowserModel) newItem(p string, d fs.DirEntry) *teatree.TreeItem {
+	var icon func(ti *teatree.TreeItem) string
+	var iconStyle func(ti *teatree.TreeItem) lipgloss.Style
+	var labelStyle func(ti *teatree.TreeItem) lipgloss.Style
 
-		var icon func(ti *teatree.TreeItem) string
-		var iconStyle func(ti *teatree.TreeItem) lipgloss.Style
-		var labelStyle func(ti *teatree.TreeItem) lipgloss.Style
-
-		canHaveChildren := d.IsDir()
-
-		labelStyle = TextColor
-		// Default the icon and style to basic file style and icon
-		// These will be overridden by the next if statement
-		icon = FileIcon
-		iconStyle = FileColor
-
-		if d.IsDir() {
-			icon = FolderIcon
-			iconStyle = FolderColor
-		} else {
-			if strings.HasSuffix(d.Name(), ".go") {
-				icon = GoFileIcon
-				iconStyle = GoFileColor
-			}
-		}
+	canHaveChildren := d.IsDir()
 
-		openFunc := func(ti *teatree.TreeItem) {
-			// This function is called when the user toggles an item that can have children. For now that only means this is a folder and we are now supposed to walk the ti's path, adding items
-			// If we have no children, then we should walk the directory.
-			// If we DO have children, they will be cached. Added a "r" refresh handler to
-			// cause a directory to be re-read, to take care of directory changes.
-			if len(ti.Children) == 0 {
-				err = fm.walk(path, ti)
-			}
+	labelStyle = TextColor
+	// Default the icon and style to basic file style and icon
+	// These will be overridden by the next if statement
+	icon = FileIcon
+	iconStyle = FileColor
+
+	if d.IsDir() {
+		icon = FolderIcon
+		iconStyle = FolderColor
+	} else {
+		if strings.HasSuffix(d.Name(), ".go") {
+			icon = GoFileIcon
+			iconStyle = GoFileColor
 		}
-		var children []*teatree.TreeItem
-		newitem := teatree.NewItem(d.Name(), canHaveChildren, children, icon, labelStyle, iconStyle, openFunc, nil, nil)
-		item.AddChildren(newitem)
+	}
 
-		if d.IsDir() && path != p {
-			// Do not descend into subdirectories
-			return filepath.SkipDir
+	// The file info is kept for the detail columns
+	var data interface{}
+	if info, err := d.Info(); err == nil {
+		data = info
+	}
+
+	var children []*teatree.TreeItem
+	newitem := teatree.NewItem(d.Name(), canHaveChildren, children, icon, labelStyle, iconStyle, nil, nil, data)
+	if d.IsDir() {
+		// Folders are read in the background the first time they are opened. After that
+		// the children are cached, and the "r" refresh handler causes a re-read to pick up
+		// any changes.
+		newitem.LoadFunc = func(ctx context.Context, ti *teatree.TreeItem) ([]*teatree.TreeItem, error) {
+			return fm.readDir(ctx, p)
 		}
+	}
+	return newitem
+}
 
-		return nil
-	})
+// readDir returns an item for every entry in the folder p, without descending into
+// subfolders.
+func (fm *FileBrowserModel) readDir(ctx context.Context, p string) ([]*teatree.TreeItem, error) {
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		log.Printf("error reading the path %q: %v\n", p, err)
+		return nil, err
+	}
+	var items []*teatree.TreeItem
+	for _, d := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items = append(items, fm.newItem(filepath.Join(p, d.Name()), d))
+	}
+	return items, nil
+}
 
+// walk reads the folder p straight away and adds its contents to item.
+func (fm *FileBrowserModel) walk(p string, item teatree.ItemHolder) error {
+	items, err := fm.readDir(context.Background(), p)
 	if err != nil {
-		log.Printf("error walking the path %q: %v\n", p, err)
 		return err
 	}
+	if len(items) > 0 {
+		item.AddChildren(items...)
+	}
 	return nil
 }
 
 // func New(dir string) tea.Model {
 func New(dir string) *FileBrowserModel {
 	fm := &FileBrowserModel{
-		dir:  dir,
-		Tree: teatree.New().(*teatree.Tree),
-	}
-	fm.info = func() {
-		log.Print("INFO")
-		log.Println("testing")
+		dir:    dir,
+		Tree:   teatree.New().(*teatree.Tree),
+		KeyMap: DefaultKeyMap(),
 	}
+	fm.Tree.SetSortMode("directories first")
+	// Show where the cursor is, since deep folders scroll the top of the tree out of view
+	fm.Tree.ShowBreadcrumb = true
+	fm.Tree.ShowStatus = true
+	// ? switches between a line of help and the full list of keys
+	fm.Tree.ShowHelp = true
+	fm.Tree.HelpKeys = fm
 	if err := fm.walk(dir, fm.Tree); err != nil {
 		log.Fatal(err)
 	}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/greenenergy/greenbubbles/teatree"
@@ -58,29 +60,50 @@ func (il *ItemList) AddItem(ie *ItemEntry) error {
 	return nil
 }
 
+// KeyMap holds the bindings the editor handles itself, on top of those of its tree.
+type KeyMap struct {
+	Done key.Binding
+	Help key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Done: key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("l", "done")),
+		Help: key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "more help")),
+	}
+}
+
 type ItemCollectionEditor struct {
 	Width       int // The width is for the whole control, giving the tree view the left half (so half this value)
 	Height      int
 	initialized bool
 	Tree        *teatree.Tree
-	//help        *help.Model
-	quitting bool
+	KeyMap      KeyMap
+	detail      *teatree.TreeItem // The item shown in the right hand pane
+	help        help.Model
+	quitting    bool
 }
 
 func NewEditor() *ItemCollectionEditor {
-	return &ItemCollectionEditor{
-		Tree: teatree.New().(*teatree.Tree),
+	ice := &ItemCollectionEditor{
+		Tree:   teatree.New().(*teatree.Tree),
+		KeyMap: DefaultKeyMap(),
+		help:   help.New(),
 	}
+	return ice
 }
 
 func (ice *ItemCollectionEditor) Init() tea.Cmd {
-	return nil
+	return ice.Tree.Init()
 }
 
 func (ice *ItemCollectionEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if !ice.initialized {
 		ice.initialized = true
 	}
+	if ice.detail == nil {
+		ice.detail = ice.Tree.ActiveItem
+	}
 
 	switch tmsg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -90,13 +113,31 @@ func (ice *ItemCollectionEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Get the full width and then pass half width onto the tree
 		msg = tea.WindowSizeMsg{
 			Width:  tmsg.Width / 2,
-			Height: tmsg.Height,
+			Height: ice.treeHeight(),
+		}
+	case teatree.ActiveChangedMsg:
+		if tmsg.Tree == ice.Tree {
+			ice.detail = tmsg.Item
 		}
 	case tea.KeyMsg:
-		switch tmsg.String() {
-		case "right", "l":
+		if ice.Tree.Typing() {
+			break
+		}
+		switch {
+		case key.Matches(tmsg, ice.KeyMap.Done):
 			ice.quitting = true
 			return ice, tea.Quit
+		case key.Matches(tmsg, ice.KeyMap.Help):
+			ice.help.ShowAll = !ice.help.ShowAll
+			if ice.help.ShowAll {
+				ice.KeyMap.Help.SetHelp("?", "less help")
+			} else {
+				ice.KeyMap.Help.SetHelp("?", "more help")
+			}
+			if ice.Height > 0 {
+				ice.Tree.Height = ice.treeHeight()
+			}
+			return ice, nil
 		}
 
 	}
@@ -112,11 +153,56 @@ func (ice *ItemCollectionEditor) View() string {
 		return "Bye!\n"
 	}
 	treeview := ice.Tree.View()
-	itemDump := IterateStructFields(ice.Tree.ActiveItem.Data)
-	activeView := strings.Join(itemDump, "\n")
+	var activeView string
+	if ice.detail != nil {
+		itemDump := IterateStructFields(ice.detail.Data)
+		activeView = strings.Join(itemDump, "\n")
+	}
 
 	s := lipgloss.JoinHorizontal(
 		lipgloss.Top, treeview, activeView,
 	)
-	return s
+	return lipgloss.JoinVertical(lipgloss.Left, s, ice.helpView())
+}
+
+// ShortHelp returns the bindings for the one line help, for help.KeyMap.
+func (ice *ItemCollectionEditor) ShortHelp() []key.Binding {
+	if ice.Tree.Typing() {
+		return ice.Tree.ShortHelp()
+	}
+	k := ice.Tree.KeyMap
+	return []key.Binding{k.Up, k.Down, k.Select, ice.KeyMap.Done, ice.KeyMap.Help}
+}
+
+// FullHelp returns every binding, grouped into columns, for help.KeyMap. The tree's Open
+// binding is left out, as the editor takes its keys for Done.
+func (ice *ItemCollectionEditor) FullHelp() [][]key.Binding {
+	columns := [][]key.Binding{{ice.KeyMap.Done, ice.KeyMap.Help}}
+	for _, column := range ice.Tree.FullHelp() {
+		var keep []key.Binding
+		for _, b := range column {
+			if !sameKeys(b, ice.Tree.KeyMap.Open) {
+				keep = append(keep, b)
+			}
+		}
+		if len(keep) > 0 {
+			columns = append(columns, keep)
+		}
+	}
+	return columns
+}
+
+func sameKeys(a, b key.Binding) bool {
+	return strings.Join(a.Keys(), ",") == strings.Join(b.Keys(), ",")
+}
+
+// helpView draws the help box that runs along the bottom of the editor.
+func (ice *ItemCollectionEditor) helpView() string {
+	ice.help.Width = ice.Width
+	return ice.help.View(ice)
+}
+
+// treeHeight is the height left for the tree once the help has been drawn.
+func (ice *ItemCollectionEditor) treeHeight() int {
+	return max(ice.Height-lipgloss.Height(ice.helpView()), 1)
 }
@@ -0,0 +1,126 @@
+package teatree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// runAccessible lets the user pick an item with a line based prompt instead of the TUI, in
+// the same way as huh's accessible fields. The items at the current level are listed with a
+// number; entering a number opens that folder or chooses that item, ".." goes back up a
+// level, and "." chooses the folder being listed.
+func (t *Tree) runAccessible(r io.Reader, w io.Writer) error {
+	styles := t.fieldStyles()
+	if t.title != "" {
+		fmt.Fprintln(w, styles.Title.Render(t.title))
+	}
+	if t.description != "" {
+		fmt.Fprintln(w, styles.Description.Render(t.description))
+	}
+
+	scanner := bufio.NewScanner(r)
+	var level *TreeItem // nil is the top of the tree
+	for {
+		all := t.Items
+		if level != nil {
+			fmt.Fprintf(w, "\n%s:\n", strings.Join(level.GetPath(), "/"))
+			if err := level.loadNow(); err != nil {
+				fmt.Fprintln(w, styles.ErrorMessage.Render(err.Error()))
+			}
+			all = level.Children
+		} else {
+			fmt.Fprintln(w)
+		}
+		// Loading and error rows, and disabled items, can't be chosen
+		var items []*TreeItem
+		for _, item := range all {
+			if !item.placeholder && !item.Disabled {
+				items = append(items, item)
+			}
+		}
+		for x, item := range items {
+			name := item.Name
+			if item.CanHaveChildren {
+				name += "/"
+			}
+			fmt.Fprintf(w, "%d. %s\n", x+1, name)
+		}
+		if len(items) == 0 {
+			fmt.Fprintln(w, "(empty)")
+		}
+
+		fmt.Fprint(w, "Choose: ")
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			return errors.New("no item chosen")
+		}
+		input := strings.TrimSpace(scanner.Text())
+
+		var chosen *TreeItem
+		switch input {
+		case "..":
+			if level == nil {
+				fmt.Fprintln(w, "Already at the top.")
+				continue
+			}
+			level, _ = level.GetParent().(*TreeItem)
+			continue
+		case ".":
+			if level == nil {
+				fmt.Fprintln(w, "Choose an item from the list.")
+				continue
+			}
+			chosen = level
+		default:
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > len(items) {
+				fmt.Fprintf(w, "Enter a number from 1 to %d, \"..\" to go up, or \".\" to choose this folder.\n", len(items))
+				continue
+			}
+			if items[n-1].CanHaveChildren {
+				level = items[n-1]
+				continue
+			}
+			chosen = items[n-1]
+		}
+
+		if err := t.validate(chosen.GetPath()); err != nil {
+			fmt.Fprintln(w, styles.ErrorMessage.Render(err.Error()))
+			continue
+		}
+		t.reveal(chosen)
+		t.SetActive(chosen)
+		t.updateValue()
+		fmt.Fprintln(w, "Chosen: "+strings.Join(chosen.GetPath(), "/"))
+		return nil
+	}
+}
+
+// loadNow fetches the children of an item straight away, for use outside of the Update
+// loop. An OpenFunc is run if the item has no children yet, as ToggleChildren would, and then
+// a LoadFunc or LoadCmd. It returns the loader's error, if it failed.
+func (ti *TreeItem) loadNow() error {
+	if ti.OpenFunc != nil && len(ti.Children) == 0 {
+		ti.OpenFunc(ti)
+	}
+	if !ti.lazy() || ti.loaded || ti.Loading() {
+		return nil
+	}
+	children, err := ti.fetch()
+	ti.finishLoad(children, err)
+	return err
+}
+
+// reveal opens every ancestor of ti, so that it is on display.
+func (t *Tree) reveal(ti *TreeItem) {
+	for par, ok := ti.GetParent().(*TreeItem); ok; par, ok = par.GetParent().(*TreeItem) {
+		par.Open = true
+	}
+	t.Invalidate()
+}
@@ -0,0 +1,100 @@
+package teatree
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAccessible(t *testing.T) {
+	tr := newTestTree(10)
+	var value []string
+	tr.Title("Pick").Value(&value)
+
+	var out strings.Builder
+	// Into a, back up, a bad entry, into b, then choose b1
+	err := tr.runAccessible(strings.NewReader("1\n..\nx\n2\n1\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(value, "/") != "b/b1" || tr.ActiveItem.Name != "b1" {
+		t.Fatalf("wrong item chosen: %v", value)
+	}
+	if !tr.Items[1].Open {
+		t.Fatal("the chosen item's parent should be opened")
+	}
+	for _, want := range []string{"Pick\n", "1. a/\n2. b/\n3. c\n", "a:\n1. a1\n2. a2\n", "Enter a number from 1 to 3", "Chosen: b/b1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// "." chooses the folder being listed, once it passes validation
+	tr.Validate(func(path []string) error {
+		if len(path) == 1 {
+			return errors.New("pick a file")
+		}
+		return nil
+	})
+	out.Reset()
+	if err := tr.runAccessible(strings.NewReader("1\n.\n2\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(value, "/") != "a/a2" || !strings.Contains(out.String(), "pick a file") {
+		t.Fatalf("validation not applied: %v\n%s", value, out.String())
+	}
+
+	if err := tr.runAccessible(strings.NewReader("1\n"), &out); err == nil {
+		t.Fatal("expected an error when the input runs out")
+	}
+}
+
+func TestAccessibleLoad(t *testing.T) {
+	tr, _ := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return []*TreeItem{NewItem("file", false, nil, nil, nil, nil, nil, nil, nil)}, nil
+	})
+	var out strings.Builder
+	if err := tr.runAccessible(strings.NewReader("1\n1\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tr.activePath(), "/") != "dir/file" {
+		t.Fatalf("children not loaded: %s", out.String())
+	}
+}
+
+func TestAccessibleLoadError(t *testing.T) {
+	tr, _ := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return nil, errors.New("permission denied")
+	})
+	off := NewItem("off", false, nil, nil, nil, nil, nil, nil, nil)
+	off.Disabled = true
+	tr.AddChildren(off)
+
+	var out strings.Builder
+	if err := tr.runAccessible(strings.NewReader("2\n1\n1\n"), &out); err == nil {
+		t.Fatalf("nothing should have been chosen:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "permission denied") || strings.Contains(out.String(), "Chosen") {
+		t.Fatalf("the load error should be shown, not offered as a choice:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "off") {
+		t.Fatalf("disabled items should not be listed:\n%s", out.String())
+	}
+}
+
+func TestAccessibleOpenFunc(t *testing.T) {
+	tr := New().(*Tree)
+	dir := NewItem("dir", true, nil, nil, nil, nil, nil, nil, nil)
+	dir.OpenFunc = func(ti *TreeItem) {
+		ti.AddChildren(NewItem("file", false, nil, nil, nil, nil, nil, nil, nil))
+	}
+	tr.AddChildren(dir)
+	var out strings.Builder
+	if err := tr.runAccessible(strings.NewReader("1\n1\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tr.activePath(), "/") != "dir/file" {
+		t.Fatalf("OpenFunc not run: %s", out.String())
+	}
+}
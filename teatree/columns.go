@@ -0,0 +1,136 @@
+package teatree
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// Column is an extra field drawn to the right of the labels on every row, turning the tree
+// into a table.
+type Column struct {
+	Title string
+	Width int // Fixed width in cells. Ignored if Flex is set.
+	// Flex shares the width left over by the fixed columns between the flexible columns and
+	// the labels, which count as a Flex of 1.
+	Flex  int
+	Align lipgloss.Position
+	Cell  func(*TreeItem) string
+}
+
+// columnGap separates the labels and each column.
+const columnGap = " "
+
+// columnLayout is the width of the labels and of each column.
+type columnLayout struct {
+	label int
+	cells []int
+}
+
+// innerWidth is the width left for rows once the theme's frame has been drawn, or 0 if the
+// tree's width isn't known.
+func (t *Tree) innerWidth() int {
+	if t.Width <= 0 {
+		return 0
+	}
+	width := t.Width
+	if t.theme != nil {
+		width -= t.fieldStyles().Base.GetHorizontalFrameSize()
+	}
+	return max(width, 1)
+}
+
+// layoutColumns works out how wide the labels and columns are. When the tree's width isn't
+// known, each part is as wide as the widest entry in rows.
+func (t *Tree) layoutColumns(rows []row) columnLayout {
+	layout := columnLayout{cells: make([]int, len(t.Columns))}
+	width := t.innerWidth()
+	if width == 0 {
+		for _, r := range rows {
+			layout.label = max(layout.label, lipgloss.Width(t.rowLabel(r)))
+		}
+		for x, col := range t.Columns {
+			layout.cells[x] = col.Width
+			if col.Flex > 0 {
+				layout.cells[x] = lipgloss.Width(col.Title)
+				for _, r := range rows {
+					layout.cells[x] = max(layout.cells[x], lipgloss.Width(cellText(col, r.item)))
+				}
+			}
+		}
+		return layout
+	}
+
+	spare := width - len(t.Columns)*len(columnGap)
+	flex := 1
+	for _, col := range t.Columns {
+		if col.Flex > 0 {
+			flex += col.Flex
+		} else {
+			spare -= col.Width
+		}
+	}
+	spare = max(spare, 0)
+	layout.label = spare
+	for x, col := range t.Columns {
+		if col.Flex > 0 {
+			layout.cells[x] = spare * col.Flex / flex
+			layout.label -= layout.cells[x]
+		} else {
+			layout.cells[x] = col.Width
+		}
+	}
+	return layout
+}
+
+// cellText returns the contents of a column for ti. Loading and error rows have no cells.
+func cellText(col Column, ti *TreeItem) string {
+	if col.Cell == nil || ti.placeholder {
+		return ""
+	}
+	return col.Cell(ti)
+}
+
+// fitWidth pads or cuts s, which may contain escape codes, to exactly width cells. Cut text
+// ends with tail.
+func fitWidth(s string, width int, align lipgloss.Position, tail string) string {
+	if lipgloss.Width(s) > width {
+		s = truncate.StringWithTail(s, uint(width), tail)
+	}
+	gap := width - lipgloss.Width(s)
+	if gap <= 0 {
+		return s
+	}
+	left := int(float64(gap) * float64(align))
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", gap-left)
+}
+
+// withColumns adds the columns for ti to the right of its rendered label, and cuts the row to
+// the tree's width.
+func (t *Tree) withColumns(label string, ti *TreeItem) string {
+	if len(t.Columns) == 0 {
+		return t.clip(label, t.innerWidth())
+	}
+	var sb strings.Builder
+	sb.WriteString(fitWidth(label, t.layout.label, lipgloss.Left, t.Ellipsis))
+	for x, col := range t.Columns {
+		sb.WriteString(columnGap)
+		sb.WriteString(fitWidth(cellText(col, ti), t.layout.cells[x], col.Align, t.Ellipsis))
+	}
+	return sb.String()
+}
+
+// columnHeader draws the titles of the columns, or returns "" if there are none.
+func (t *Tree) columnHeader() string {
+	if len(t.Columns) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", t.layout.label))
+	for x, col := range t.Columns {
+		sb.WriteString(columnGap)
+		sb.WriteString(fitWidth(col.Title, t.layout.cells[x], col.Align, t.Ellipsis))
+	}
+	return t.Styles.Header.Render(sb.String())
+}
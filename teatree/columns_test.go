@@ -0,0 +1,54 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestColumns(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Columns = []Column{
+		{Title: "Kind", Flex: 1, Cell: func(ti *TreeItem) string {
+			if ti.CanHaveChildren {
+				return "folder"
+			}
+			return "file"
+		}},
+		{Title: "Len", Width: 3, Align: lipgloss.Right, Cell: func(ti *TreeItem) string {
+			return strings.Repeat("#", len(ti.Name))
+		}},
+	}
+	tr.Update(tea.WindowSizeMsg{Width: 30, Height: 10})
+
+	lines := strings.Split(tr.View(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and three rows, got %d lines", len(lines))
+	}
+	// 30 wide, less two gaps and the fixed column, leaves 25 shared by the labels and Kind
+	if lines[0] != strings.Repeat(" ", 13)+" Kind        "+" Len" {
+		t.Fatalf("bad header %q", lines[0])
+	}
+	for _, line := range lines {
+		if lipgloss.Width(line) != 30 {
+			t.Fatalf("line is %d wide: %q", lipgloss.Width(line), line)
+		}
+	}
+	if !strings.HasSuffix(lines[3], " file"+strings.Repeat(" ", 11)+"#") {
+		t.Fatalf("bad row %q", lines[3])
+	}
+
+	// The header takes a line from the rows, and clicks allow for it
+	if tr.viewHeight() != 9 || tr.itemAtLine(1) != tr.Items[0] {
+		t.Fatal("header not accounted for")
+	}
+
+	// Without a width, each column is as wide as its widest entry
+	tr.Width = 0
+	lines = strings.Split(tr.View(), "\n")
+	if !strings.HasSuffix(lines[0], " Kind   Len") || !strings.HasSuffix(lines[1], " folder   #") {
+		t.Fatalf("bad natural layout:\n%s", strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,94 @@
+package teatree
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// These messages are returned as commands from Tree.Update, so that a host model can react to
+// what happens in the tree in its own Update. Tree is the tree the event came from, to tell
+// several trees apart, and Path is Item.GetPath() at the time of the event.
+
+// ActiveChangedMsg is sent when the cursor moves to a different item. Previous is the item it
+// moved from, and may be nil.
+type ActiveChangedMsg struct {
+	Tree     *Tree
+	Item     *TreeItem
+	Path     []string
+	Previous *TreeItem
+}
+
+// ExpandedMsg is sent when an item is opened.
+type ExpandedMsg struct {
+	Tree *Tree
+	Item *TreeItem
+	Path []string
+}
+
+// CollapsedMsg is sent when an item is closed.
+type CollapsedMsg struct {
+	Tree *Tree
+	Item *TreeItem
+	Path []string
+}
+
+// SelectedMsg is sent when the user chooses an item with the Select binding.
+type SelectedMsg struct {
+	Tree *Tree
+	Item *TreeItem
+	Path []string
+}
+
+// RenamedMsg is sent when an item is renamed inline. Path is the item's new path.
+type RenamedMsg struct {
+	Tree    *Tree
+	Item    *TreeItem
+	Path    []string
+	OldName string
+}
+
+// MovedMsg is sent for each item moved with Paste. OldParent and NewParent are the Tree
+// itself for the top level.
+type MovedMsg struct {
+	Tree      *Tree
+	Item      *TreeItem
+	Path      []string
+	OldParent ItemHolder
+	NewParent ItemHolder
+}
+
+// UndoneMsg is sent when an edit is undone, so that the host model can revert its own
+// changes to match.
+type UndoneMsg struct {
+	Tree *Tree
+	Edit Edit
+}
+
+// RedoneMsg is sent when an undone edit is made again.
+type RedoneMsg struct {
+	Tree *Tree
+	Edit Edit
+}
+
+// SortedMsg is sent when the Sort binding switches the tree to the next of its SortModes.
+type SortedMsg struct {
+	Tree *Tree
+	Mode string
+}
+
+// emit queues msg to be delivered through the command returned by the next Update.
+func (t *Tree) emit(msg tea.Msg) {
+	t.queue(func() tea.Msg {
+		return msg
+	})
+}
+
+// emitToggled reports an item being opened or closed.
+func (ti *TreeItem) emitToggled() {
+	t := ti.parentTree
+	if t == nil {
+		return
+	}
+	if ti.Open {
+		t.emit(ExpandedMsg{Tree: t, Item: ti, Path: ti.GetPath()})
+	} else {
+		t.emit(CollapsedMsg{Tree: t, Item: ti, Path: ti.GetPath()})
+	}
+}
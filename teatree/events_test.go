@@ -0,0 +1,56 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	tr := newTestTree(10)
+	a := tr.ActiveItem
+
+	_, cmd := tr.Update(keyMsg("j"))
+	msgs := run(cmd)
+	if len(msgs) != 1 {
+		t.Fatalf("expected one message, got %d", len(msgs))
+	}
+	changed, ok := msgs[0].(ActiveChangedMsg)
+	if !ok || changed.Tree != tr || changed.Item != tr.ActiveItem || changed.Previous != a {
+		t.Fatalf("bad ActiveChangedMsg: %#v", msgs[0])
+	}
+	if strings.Join(changed.Path, "/") != strings.Join(tr.ActiveItem.GetPath(), "/") {
+		t.Fatalf("bad path %v", changed.Path)
+	}
+
+	b := tr.ActiveItem
+	_, cmd = tr.Update(keyMsg(" "))
+	if msgs = run(cmd); len(msgs) != 1 {
+		t.Fatalf("expected one message, got %d", len(msgs))
+	}
+	if expanded, ok := msgs[0].(ExpandedMsg); !ok || expanded.Item != b {
+		t.Fatalf("expected ExpandedMsg, got %#v", msgs[0])
+	}
+	_, cmd = tr.Update(keyMsg(" "))
+	if msgs = run(cmd); len(msgs) != 1 {
+		t.Fatalf("expected one message, got %d", len(msgs))
+	}
+	if collapsed, ok := msgs[0].(CollapsedMsg); !ok || collapsed.Item != b {
+		t.Fatalf("expected CollapsedMsg, got %#v", msgs[0])
+	}
+
+	_, cmd = tr.Update(keyMsg("enter"))
+	if msgs = run(cmd); len(msgs) != 1 {
+		t.Fatalf("expected one message, got %d", len(msgs))
+	}
+	if selected, ok := msgs[0].(SelectedMsg); !ok || selected.Item != b {
+		t.Fatalf("expected SelectedMsg, got %#v", msgs[0])
+	}
+
+	// Nothing happened, so nothing is reported
+	_, cmd = tr.Update(keyMsg("k"))
+	run(cmd)
+	_, cmd = tr.Update(keyMsg("k"))
+	if msgs = run(cmd); len(msgs) != 0 {
+		t.Fatalf("expected no messages at the top, got %#v", msgs)
+	}
+}
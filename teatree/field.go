@@ -0,0 +1,281 @@
+package teatree
+
+import (
+	"os"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// huh doesn't export the commands that move a form on to the next or previous field, so they
+// are borrowed from a Note, which returns one of them for any key press.
+var nextField, prevField = formCommands()
+
+func formCommands() (next, prev tea.Cmd) {
+	note := huh.NewNote()
+	note.WithKeyMap(huh.NewDefaultKeyMap())
+	_, next = note.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, prev = note.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	return next, prev
+}
+
+// plainStyles are used to draw the title, description and errors of a tree that hasn't been
+// given a huh theme.
+var plainStyles = huh.FieldStyles{
+	Title:          lipgloss.NewStyle().Bold(true),
+	Description:    lipgloss.NewStyle().Faint(true),
+	ErrorIndicator: lipgloss.NewStyle().Foreground(lipgloss.Color("9")).SetString(" *"),
+	ErrorMessage:   lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+}
+
+// Title sets the title shown above the tree.
+func (t *Tree) Title(title string) *Tree {
+	t.title = title
+	return t
+}
+
+// Description sets the text shown between the title and the tree.
+func (t *Tree) Description(description string) *Tree {
+	t.description = description
+	return t
+}
+
+// Key sets the key the tree's value is stored under in a huh.Form.
+func (t *Tree) Key(key string) *Tree {
+	t.key = key
+	return t
+}
+
+// Validate sets a function that checks the path of the active item. The form can't move on
+// from the tree while it returns an error.
+func (t *Tree) Validate(validate func([]string) error) *Tree {
+	t.validate = validate
+	return t
+}
+
+// Value binds the path of the active item to value, which is kept up to date as the cursor
+// moves. If value already holds a path, the cursor starts on that item, when it exists.
+func (t *Tree) Value(value *[]string) *Tree {
+	t.value = value
+	if len(*value) > 0 {
+		if ti := t.itemAt(*value); ti != nil {
+			t.SetActive(ti)
+		}
+	}
+	return t
+}
+
+// itemAt finds the loaded item with the given path.
+func (t *Tree) itemAt(path []string) *TreeItem {
+	items := t.Items
+	var found *TreeItem
+	for _, name := range path {
+		found = nil
+		for _, item := range items {
+			if item.Name == name {
+				found = item
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		items = found.Children
+	}
+	return found
+}
+
+// activePath returns the path of the active item, or nil if there isn't one.
+func (t *Tree) activePath() []string {
+	if t.ActiveItem == nil {
+		return nil
+	}
+	return t.ActiveItem.GetPath()
+}
+
+// updateValue copies the path of the active item to the bound value.
+func (t *Tree) updateValue() {
+	if t.value != nil {
+		*t.value = t.activePath()
+	}
+}
+
+// updateField handles the keys that move a form between fields, which are only bound when
+// the tree is part of a form. It reports whether msg was one of them.
+func (t *Tree) updateField(msg tea.KeyMsg) (tea.Cmd, bool) {
+	t.err = nil
+	switch {
+	case key.Matches(msg, t.keymap.Prev):
+		if t.err = t.validate(t.activePath()); t.err != nil {
+			return nil, true
+		}
+		return prevField, true
+	case key.Matches(msg, t.keymap.Next, t.keymap.Submit):
+		if t.err = t.validate(t.activePath()); t.err != nil {
+			return nil, true
+		}
+		return nextField, true
+	}
+	return nil, false
+}
+
+// fieldStyles returns the theme styles for the title, description and frame, for the tree's
+// current focus.
+func (t *Tree) fieldStyles() huh.FieldStyles {
+	switch {
+	case t.theme == nil:
+		return plainStyles
+	case t.focused:
+		return t.theme.Focused
+	}
+	return t.theme.Blurred
+}
+
+// fieldHeader draws the title and description, or returns "" if there are neither.
+func (t *Tree) fieldHeader() string {
+	styles := t.fieldStyles()
+	var header string
+	if t.title != "" || t.err != nil {
+		header = styles.Title.Render(t.title)
+		if t.err != nil {
+			header += styles.ErrorIndicator.String()
+		}
+	}
+	if t.description != "" {
+		if header != "" {
+			header += "\n"
+		}
+		header += styles.Description.Render(t.description)
+	}
+	return header
+}
+
+// frameTop and frameLeft are the number of lines and columns taken up by the theme's border
+// and padding above and to the left of the tree.
+func (t *Tree) frameTop() int {
+	base := t.fieldStyles().Base
+	return base.GetMarginTop() + base.GetBorderTopSize() + base.GetPaddingTop()
+}
+
+func (t *Tree) frameLeft() int {
+	base := t.fieldStyles().Base
+	return base.GetMarginLeft() + base.GetBorderLeftSize() + base.GetPaddingLeft()
+}
+
+// Blur is called by a form when the tree loses focus. The value is validated, and copied to
+// the bound value.
+func (t *Tree) Blur() tea.Cmd {
+	t.focused = false
+	t.CancelRename()
+	t.updateValue()
+	t.err = t.validate(t.activePath())
+	return nil
+}
+
+// Focus is called by a form when the tree gains focus. Forms never call a field's Init, and
+// drop messages for fields without focus, so this is where funcs handed to Post while the
+// tree was blurred are applied, and the command waiting for more is started again.
+func (t *Tree) Focus() tea.Cmd {
+	t.focused = true
+	t.applyPosted()
+	return t.waitForPost()
+}
+
+// Focused reports whether the tree has focus.
+func (t *Tree) Focused() bool {
+	return t.focused
+}
+
+func (t *Tree) Error() error {
+	return t.err
+}
+
+// Run runs the field individually. In accessible mode a line based prompt is used instead of
+// the TUI.
+func (t *Tree) Run() error {
+	if t.accessible {
+		return t.runAccessible(os.Stdin, os.Stdout)
+	}
+	return huh.Run(t)
+}
+
+// Skip returns whether this input should be skipped or not.
+func (t *Tree) Skip() bool {
+	return false
+}
+
+// KeyBinds returns the tree's live key bindings, for display in huh's help footer.
+func (t *Tree) KeyBinds() []key.Binding {
+	t.updateKeys()
+	k := t.KeyMap
+	return []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown, k.GoToTop, k.GoToLast, k.Back, k.Open, k.Space, k.ScrollLeft, k.ScrollRight, k.Select, k.Check, k.Sort, k.Search, k.NextMatch, k.PrevMatch, k.CancelSearch, k.Rename, k.AcceptRename, k.CancelRename, k.Cut, k.PasteAfter, k.PasteInside, k.Undo, k.Redo, k.Help, k.ExpandAll, k.CollapseAll, k.OpenFold, k.CloseFold, k.OpenFoldAll, t.keymap.Prev, t.keymap.Submit, t.keymap.Next}
+}
+
+// GetValue returns the field's value. In SelectSingle mode this is the path of the active
+// item, as a []string. Otherwise it is the path of every checked item, as a [][]string.
+func (t *Tree) GetValue() any {
+	if t.SelectionMode != SelectSingle {
+		var paths [][]string
+		for _, ti := range t.Checked() {
+			paths = append(paths, ti.GetPath())
+		}
+		return paths
+	}
+	if t.ActiveItem == nil {
+		return []string(nil)
+	}
+	return t.ActiveItem.GetPath()
+}
+
+// GetKey returns the field's key.
+func (t *Tree) GetKey() string {
+	return t.key
+}
+
+// WithHeight sets the height of the input field.
+func (t *Tree) WithHeight(height int) huh.Field {
+	t.Height = height
+	return t
+}
+
+// WithPosition sets the position of the input field, which decides whether the form keys
+// go back, move on, or submit.
+func (t *Tree) WithPosition(p huh.FieldPosition) huh.Field {
+	t.keymap.Prev.SetEnabled(!p.IsFirst())
+	t.keymap.Next.SetEnabled(!p.IsLast())
+	t.keymap.Submit.SetEnabled(p.IsLast())
+	return t
+}
+
+// WithTheme sets the theme on a field. Unless the tree's styles were set with SetStyles,
+// they are replaced by ones taken from the theme.
+func (t *Tree) WithTheme(theme *huh.Theme) huh.Field {
+	t.theme = theme
+	if theme != nil && !t.ownStyles {
+		t.Styles = StylesFromTheme(theme)
+	}
+	return t
+}
+
+// WithAccessible sets the accessible mode of the input field.
+func (t *Tree) WithAccessible(accessible bool) huh.Field {
+	t.accessible = accessible
+	return t
+}
+
+// WithKeyMap sets the keymap on an input field. The tree uses the Prev, Next and Submit
+// bindings of the Input keymap; these take priority over the tree's own KeyMap. A form calls
+// this when the tree is added to it, so the tree is blurred until the form focuses it.
+func (t *Tree) WithKeyMap(k *huh.KeyMap) huh.Field {
+	t.keymap = k.Input
+	t.focused = false
+	return t
+}
+
+// WithWidth sets the width of the input field.
+func (t *Tree) WithWidth(width int) huh.Field {
+	t.Width = width
+	return t
+}
@@ -0,0 +1,63 @@
+package teatree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+)
+
+func TestFormField(t *testing.T) {
+	tr := newTestTree(10)
+	var value []string
+	tr.Title("Pick one").Description("anything but c").Value(&value)
+	tr.Validate(func(path []string) error {
+		if strings.Join(path, "/") == "c" {
+			return errors.New("not c")
+		}
+		return nil
+	})
+	tr.WithKeyMap(huh.NewDefaultKeyMap())
+	tr.WithPosition(huh.FieldPosition{Field: 0, FirstField: 0, LastField: 1})
+	tr.Focus()
+
+	view := tr.View()
+	if !strings.HasPrefix(view, "Pick one\nanything but c\n") {
+		t.Fatalf("title and description missing:\n%s", view)
+	}
+	if tr.viewHeight() != 8 {
+		t.Fatalf("expected 8 lines for items, got %d", tr.viewHeight())
+	}
+
+	tr.Update(keyMsg("j"))
+	if strings.Join(value, "/") != "b" {
+		t.Fatalf("value not kept up to date: %v", value)
+	}
+
+	_, cmd := tr.Update(keyMsg("enter"))
+	if msgs := run(cmd); len(msgs) != 1 || msgs[0] != nextField() {
+		t.Fatalf("expected the form to move on, got %#v", msgs)
+	}
+
+	tr.Update(keyMsg("G"))
+	_, cmd = tr.Update(keyMsg("enter"))
+	if tr.Error() == nil || len(run(cmd)) != 0 {
+		t.Fatal("an invalid value should hold the form on the tree")
+	}
+	if !strings.HasPrefix(tr.View(), "Pick one *") {
+		t.Fatalf("error not indicated:\n%s", tr.View())
+	}
+
+	tr.Update(keyMsg("k"))
+	if tr.Error() != nil {
+		t.Fatal("error should clear on the next key")
+	}
+	// The first field has nowhere to go back to
+	_, cmd = tr.Update(keyMsg("shift+tab"))
+	for _, msg := range run(cmd) {
+		if msg == prevField() {
+			t.Fatal("prev should be disabled on the first field")
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package teatree
+
+import "errors"
+
+var (
+	// SkipChildren is returned by a WalkFunc to carry on past the item's children without
+	// visiting them.
+	SkipChildren = errors.New("skip children")
+	// StopWalk is returned by a WalkFunc to end the walk early. Walk returns nil.
+	StopWalk = errors.New("stop walk")
+)
+
+// WalkFunc is called by Walk for each item. Returning SkipChildren or StopWalk controls the
+// walk; any other error stops it and is returned by Walk.
+type WalkFunc func(ti *TreeItem) error
+
+// Walk calls fn for every item in the tree, depth first, whether it is open or not. Children
+// that haven't been loaded aren't visited.
+func (t *Tree) Walk(fn WalkFunc) error {
+	return ignoreStop(walkItems(t.Items, fn))
+}
+
+// Walk calls fn for the item, and then every item below it, depth first.
+func (ti *TreeItem) Walk(fn WalkFunc) error {
+	return ignoreStop(walkItems([]*TreeItem{ti}, fn))
+}
+
+func walkItems(items []*TreeItem, fn WalkFunc) error {
+	for _, ti := range items {
+		if ti.placeholder {
+			continue
+		}
+		switch err := fn(ti); err {
+		case nil:
+			if err := walkItems(ti.Children, fn); err != nil {
+				return err
+			}
+		case SkipChildren:
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func ignoreStop(err error) error {
+	if err == StopWalk {
+		return nil
+	}
+	return err
+}
+
+// FindFunc returns the first item, in the order Walk visits them, that pred is true for, or
+// nil if there isn't one.
+func (t *Tree) FindFunc(pred func(*TreeItem) bool) *TreeItem {
+	var found *TreeItem
+	t.Walk(func(ti *TreeItem) error {
+		if pred(ti) {
+			found = ti
+			return StopWalk
+		}
+		return nil
+	})
+	return found
+}
+
+// FindByPath returns the item at path, as returned by GetPath, or nil if there isn't one.
+// Where siblings share a name, the first of them is taken.
+func (t *Tree) FindByPath(path []string) *TreeItem {
+	if ti, found, _ := t.resolvePath(path); found {
+		return ti
+	}
+	return nil
+}
+
+// FindByID returns the item with the given ID, or nil if there isn't one in the tree.
+func (t *Tree) FindByID(id string) *TreeItem {
+	if id == "" {
+		return nil
+	}
+	if ti := t.ids[id]; ti != nil && ti.ID == id && ti.parentTree == t {
+		return ti
+	}
+	// The ID may have been set on an item that was already in the tree
+	ti := t.FindFunc(func(ti *TreeItem) bool {
+		return ti.ID == id
+	})
+	if ti != nil {
+		t.index(ti)
+	}
+	return ti
+}
+
+// SetID changes the item's ID, keeping the index of the tree it is part of up to date.
+func (ti *TreeItem) SetID(id string) {
+	t := ti.parentTree
+	t.unindex(ti)
+	ti.ID = id
+	t.index(ti)
+}
+
+// index adds ti to the tree's ID index, if it has an ID.
+func (t *Tree) index(ti *TreeItem) {
+	if t == nil || ti.ID == "" {
+		return
+	}
+	if t.ids == nil {
+		t.ids = map[string]*TreeItem{}
+	}
+	t.ids[ti.ID] = ti
+}
+
+// unindex takes ti out of the tree's ID index.
+func (t *Tree) unindex(ti *TreeItem) {
+	if t != nil && ti.ID != "" && t.ids[ti.ID] == ti {
+		delete(t.ids, ti.ID)
+	}
+}
@@ -0,0 +1,86 @@
+package teatree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFindByID(t *testing.T) {
+	tr := newTestTree(10)
+	item := NewItem("x", false, nil, nil, nil, nil, nil, nil, nil)
+	item.ID = "server-1"
+	a := tr.Items[0]
+	a.AddChildren(item)
+	if tr.FindByID("server-1") != item {
+		t.Fatal("item not found by the ID it was added with")
+	}
+
+	// The ID stays with the item through moves and renames
+	tr.MoveItem(item, tr.Items[1], 0)
+	item.Name = "y"
+	if tr.FindByID("server-1") != item {
+		t.Fatal("item not found after moving")
+	}
+
+	item.SetID("server-2")
+	if tr.FindByID("server-1") != nil || tr.FindByID("server-2") != item {
+		t.Fatal("SetID not reflected in the index")
+	}
+	// Setting the field directly is picked up too
+	a.Children[0].ID = "first"
+	if tr.FindByID("first") != a.Children[0] {
+		t.Fatal("ID set on an attached item not found")
+	}
+
+	tr.Items[1].RemoveItem(item)
+	if tr.FindByID("server-2") != nil {
+		t.Fatal("removed item still found")
+	}
+}
+
+func TestFindByPath(t *testing.T) {
+	tr := newTestTree(10)
+	if ti := tr.FindByPath([]string{"b", "b1"}); ti == nil || ti != tr.Items[1].Children[0] {
+		t.Fatalf("got %v", ti)
+	}
+	if tr.FindByPath([]string{"b", "nope"}) != nil || tr.FindByPath(nil) != nil {
+		t.Fatal("missing paths should give nil")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tr := newTestTree(10)
+	var seen []string
+	tr.Walk(func(ti *TreeItem) error {
+		seen = append(seen, ti.Name)
+		if ti.Name == "a" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if got := strings.Join(seen, ","); got != "a,b,b1,c" {
+		t.Fatalf("skip: got %s", got)
+	}
+
+	seen = nil
+	err := tr.Walk(func(ti *TreeItem) error {
+		seen = append(seen, ti.Name)
+		if ti.Name == "a2" {
+			return StopWalk
+		}
+		return nil
+	})
+	if got := strings.Join(seen, ","); err != nil || got != "a,a1,a2" {
+		t.Fatalf("stop: got %s, %v", got, err)
+	}
+
+	boom := errors.New("boom")
+	if err := tr.Walk(func(*TreeItem) error { return boom }); err != boom {
+		t.Fatalf("expected the error back, got %v", err)
+	}
+
+	if ti := tr.FindFunc(func(ti *TreeItem) bool { return strings.HasSuffix(ti.Name, "1") }); ti == nil || ti.Name != "a1" {
+		t.Fatalf("FindFunc: got %v", ti)
+	}
+}
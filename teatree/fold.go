@@ -0,0 +1,144 @@
+package teatree
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExpandAll opens every item in the tree that has children. Items whose children have yet to
+// be loaded, or are added by an OpenFunc, are left closed, so that the whole of a large lazy
+// tree isn't read in at once.
+func (t *Tree) ExpandAll() {
+	for _, ti := range t.Items {
+		ti.ExpandAll()
+	}
+	t.cursorToVisible()
+}
+
+// CollapseAll closes every item in the tree. The cursor moves up to the top level.
+func (t *Tree) CollapseAll() {
+	for _, ti := range t.Items {
+		ti.CollapseAll()
+	}
+	t.cursorToVisible()
+}
+
+// ExpandToDepth opens the items with children in the first n levels of the tree and closes
+// everything below them, so that n levels below the top are on display. Like ExpandAll, it
+// doesn't load anything.
+func (t *Tree) ExpandToDepth(n int) {
+	var walk func([]*TreeItem, int)
+	walk = func(items []*TreeItem, depth int) {
+		for _, ti := range items {
+			if depth < n {
+				ti.expand()
+			} else {
+				ti.collapse()
+			}
+			walk(ti.Children, depth+1)
+		}
+	}
+	walk(t.Items, 0)
+	t.cursorToVisible()
+}
+
+// RevealPath opens each item along path, running their OpenFuncs and LoadFuncs, then puts the
+// cursor on the item at the end of it and scrolls it into view. Items that are still loading
+// are opened when their children arrive, so the returned command must be run for the whole
+// path to be revealed. If the path doesn't exist, the cursor goes to the deepest item on it
+// that does.
+func (t *Tree) RevealPath(path []string) tea.Cmd {
+	s := TreeState{Viewtop: t.Viewtop}
+	if t.pendingState != nil {
+		// Carry on with whatever an earlier ApplyState is still waiting for
+		s = *t.pendingState
+	}
+	for x := 1; x < len(path); x++ {
+		s.Open = append(s.Open, path[:x])
+	}
+	s.Active = path
+	return t.ApplyState(s)
+}
+
+// ExpandAll opens the item and everything below it that has children, without loading
+// anything.
+func (ti *TreeItem) ExpandAll() {
+	ti.expand()
+	for _, child := range ti.Children {
+		child.ExpandAll()
+	}
+}
+
+// CollapseAll closes the item and everything below it.
+func (ti *TreeItem) CollapseAll() {
+	for _, child := range ti.Children {
+		child.CollapseAll()
+	}
+	ti.collapse()
+}
+
+// expand opens ti if it is closed and already has children.
+func (ti *TreeItem) expand() {
+	if !ti.Open && ti.CanHaveChildren && len(ti.Children) > 0 && !ti.placeholder {
+		ti.ToggleChildren()
+	}
+}
+
+// collapse closes ti if it is open.
+func (ti *TreeItem) collapse() {
+	if ti.Open && ti.CanHaveChildren {
+		ti.ToggleChildren()
+	}
+}
+
+// CloseFold closes the active item if it is open, or else the item it is in, moving the
+// cursor up to it.
+func (t *Tree) CloseFold() {
+	ai := t.ActiveItem
+	if ai == nil {
+		return
+	}
+	if !ai.Open || !ai.CanHaveChildren {
+		par, ok := ai.GetParent().(*TreeItem)
+		if !ok {
+			return
+		}
+		ai = par
+	}
+	ai.collapse()
+	t.SetActive(ai)
+}
+
+// cursorToVisible moves the cursor up to the closest ancestor that is on display, after the
+// item it was on has been hidden.
+func (t *Tree) cursorToVisible() {
+	t.Invalidate()
+	ti := t.ActiveItem
+	for ti != nil && t.rowOf(ti) < 0 {
+		ti, _ = ti.GetParent().(*TreeItem)
+	}
+	if ti != nil {
+		t.SetActive(ti)
+	}
+	t.scrollToActive()
+}
+
+// updateFold handles the key after the Fold prefix.
+func (t *Tree) updateFold(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, t.KeyMap.ExpandAll):
+		t.ExpandAll()
+	case key.Matches(msg, t.KeyMap.CollapseAll):
+		t.CollapseAll()
+	case key.Matches(msg, t.KeyMap.OpenFold):
+		t.OpenChild()
+	case key.Matches(msg, t.KeyMap.CloseFold):
+		t.CloseFold()
+	case key.Matches(msg, t.KeyMap.OpenFoldAll):
+		if t.ActiveItem != nil {
+			t.ActiveItem.ExpandAll()
+			// The cursor item may have been closed with nothing loaded below it
+			t.OpenChild()
+		}
+	}
+}
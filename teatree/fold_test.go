@@ -0,0 +1,80 @@
+package teatree
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExpandCollapse(t *testing.T) {
+	tr := newTestTree(20)
+	a := tr.Items[0]
+	a.Children[0].AddChildren(NewItem("deep", false, nil, nil, nil, nil, nil, nil, nil))
+
+	tr.Update(keyMsg("z"))
+	tr.Update(keyMsg("R"))
+	if got := names(tr.visibleItems()); got != "a,a1,deep,a2,b,b1,c" {
+		t.Fatalf("zR: got %s", got)
+	}
+
+	tr.SetActive(a.Children[0].Children[0])
+	tr.Update(keyMsg("z"))
+	tr.Update(keyMsg("c"))
+	if got := names(tr.visibleItems()); got != "a,a1,a2,b,b1,c" || tr.ActiveItem != a.Children[0] {
+		t.Fatalf("zc: got %s, cursor on %q", got, tr.ActiveItem.Name)
+	}
+
+	tr.ExpandToDepth(1)
+	if got := names(tr.visibleItems()); got != "a,a1,a2,b,b1,c" {
+		t.Fatalf("depth 1: got %s", got)
+	}
+
+	tr.Update(keyMsg("z"))
+	tr.Update(keyMsg("M"))
+	if got := names(tr.visibleItems()); got != "a,b,c" || tr.ActiveItem != a {
+		t.Fatalf("zM: got %s, cursor on %q", got, tr.ActiveItem.Name)
+	}
+
+	tr.Update(keyMsg("z"))
+	tr.Update(keyMsg("O"))
+	if got := names(tr.visibleItems()); got != "a,a1,deep,a2,b,c" {
+		t.Fatalf("zO: got %s", got)
+	}
+
+	// A key that isn't a fold command after z is dropped along with the prefix
+	tr.Update(keyMsg("z"))
+	tr.Update(keyMsg("j"))
+	tr.Update(keyMsg("j"))
+	if tr.ActiveItem.Name != "a1" {
+		t.Fatalf("cursor on %q, want a1", tr.ActiveItem.Name)
+	}
+}
+
+func TestRevealPath(t *testing.T) {
+	loads := 0
+	var load LoadFunc
+	load = func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		loads++
+		return []*TreeItem{
+			{Name: ti.Name + "1", CanHaveChildren: true, LoadFunc: load},
+			{Name: ti.Name + "2"},
+		}, nil
+	}
+	tr, _ := newLazyTree(load)
+	tr.Height = 3
+
+	// Keep feeding messages back in until the loads are done
+	msgs := run(tr.RevealPath([]string{"dir", "dir1", "dir11"}))
+	for len(msgs) > 0 {
+		_, cmd := tr.Update(msgs[0])
+		msgs = append(msgs[1:], run(cmd)...)
+	}
+	if tr.ActiveItem == nil || tr.ActiveItem.Name != "dir11" {
+		t.Fatalf("cursor not on the revealed item: %v", tr.ActiveItem)
+	}
+	if tr.ActiveLine < 0 || tr.ActiveLine >= tr.viewHeight() || tr.rowOf(tr.ActiveItem)-tr.Viewtop != tr.ActiveLine {
+		t.Fatalf("revealed item not scrolled into view: line %d, top %d", tr.ActiveLine, tr.Viewtop)
+	}
+	if loads != 2 {
+		t.Fatalf("expected 2 loads, got %d", loads)
+	}
+}
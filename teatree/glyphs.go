@@ -0,0 +1,142 @@
+package teatree
+
+import "strings"
+
+// GlyphSet is the set of symbols used to draw the tree. The chevrons and checkboxes should
+// each be the same width as the others in their group, so that the rows line up.
+type GlyphSet struct {
+	Open   string // Chevron of an open item
+	Closed string // Chevron of a closed item
+	Leaf   string // Drawn in place of the chevron on items that can't have children
+
+	Checked   string
+	Unchecked string
+	Partial   string
+
+	// Guide lines, drawn when Tree.Guides is set
+	Vertical   string // Continues the line down past a subtree
+	Branch     string // Joins an item that has siblings below it
+	LastBranch string // Joins the last item of a list
+	Horizontal string // Runs from the branch to the item
+
+	Separator string // Between the parts of the breadcrumb
+}
+
+var (
+	// NerdFontGlyphs uses Material Design chevrons, which need a patched Nerd Font. This is the
+	// default.
+	NerdFontGlyphs = GlyphSet{
+		Open:       ChevronDown,
+		Closed:     ChevronRight,
+		Leaf:       NoChevron,
+		Checked:    CheckboxChecked,
+		Unchecked:  CheckboxUnchecked,
+		Partial:    CheckboxPartial,
+		Vertical:   "│",
+		Branch:     "├",
+		LastBranch: "└",
+		Horizontal: "─",
+		Separator:  " \uE0B1 ", // Powerline thin arrow
+	}
+	// UnicodeGlyphs works with any font that has the geometric shapes block.
+	UnicodeGlyphs = GlyphSet{
+		Open:       "▾",
+		Closed:     "▸",
+		Leaf:       " ",
+		Checked:    CheckboxChecked,
+		Unchecked:  CheckboxUnchecked,
+		Partial:    CheckboxPartial,
+		Vertical:   "│",
+		Branch:     "├",
+		LastBranch: "└",
+		Horizontal: "─",
+		Separator:  " › ",
+	}
+	// ASCIIGlyphs works on any terminal.
+	ASCIIGlyphs = GlyphSet{
+		Open:       "-",
+		Closed:     "+",
+		Leaf:       " ",
+		Checked:    CheckboxChecked,
+		Unchecked:  CheckboxUnchecked,
+		Partial:    CheckboxPartial,
+		Vertical:   "|",
+		Branch:     "|",
+		LastBranch: "`",
+		Horizontal: "-",
+		Separator:  " > ",
+	}
+)
+
+// DefaultIndentWidth is the number of cells each level of the tree is indented by.
+const DefaultIndentWidth = 2
+
+// chevron returns the symbol showing whether ti is open, closed or can't be opened.
+// OpenChildrenSymbol and ClosedChildrenSymbol take precedence over the glyph set.
+func (t *Tree) chevron(ti *TreeItem) string {
+	switch {
+	case ti.CanHaveChildren && ti.Open && t.OpenChildrenSymbol != "":
+		return t.OpenChildrenSymbol
+	case ti.CanHaveChildren && !ti.Open && t.ClosedChildrenSymbol != "":
+		return t.ClosedChildrenSymbol
+	}
+	return t.Glyphs.chevron(ti)
+}
+
+// chevron returns the glyph showing whether ti is open, closed or can't be opened.
+func (g GlyphSet) chevron(ti *TreeItem) string {
+	switch {
+	case !ti.CanHaveChildren:
+		return g.Leaf
+	case ti.Open:
+		return g.Open
+	}
+	return g.Closed
+}
+
+// indent returns what is drawn in front of the chevron on a row: blank space, or guide lines
+// joining the item to its parent and showing where its ancestors' lists carry on.
+func (t *Tree) indent(r row) string {
+	width := max(t.IndentWidth, 0)
+	if !t.Guides || width == 0 || r.depth == 0 {
+		return strings.Repeat(" ", r.depth*width)
+	}
+
+	g := t.Glyphs
+	segments := make([]string, r.depth)
+	branch := g.Branch
+	if r.last {
+		branch = g.LastBranch
+	}
+	segments[r.depth-1] = guide(branch, g.Horizontal, width)
+
+	item := r.item
+	for x := r.depth - 2; x >= 0; x-- {
+		item, _ = item.GetParent().(*TreeItem)
+		if item == nil || t.lastInList(item) {
+			segments[x] = strings.Repeat(" ", width)
+		} else {
+			segments[x] = g.Vertical + strings.Repeat(" ", width-1)
+		}
+	}
+	return strings.Join(segments, "")
+}
+
+// guide draws a branch of the given width, like "├─" or "└── ".
+func guide(branch, horizontal string, width int) string {
+	switch {
+	case width == 1:
+		return branch
+	case width == 2:
+		return branch + horizontal
+	}
+	return branch + strings.Repeat(horizontal, width-2) + " "
+}
+
+// lastInList reports whether ti is the last of its siblings on display.
+func (t *Tree) lastInList(ti *TreeItem) bool {
+	if x := t.rowOf(ti); x >= 0 {
+		return t.rows[x].last
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuides(t *testing.T) {
+	tr := newTestTree(20)
+	tr.Glyphs = ASCIIGlyphs
+	tr.Guides = true
+	a := tr.Items[0]
+	a.OpenChildren()
+	a.Children[0].AddChildren(NewItem("deep", false, nil, nil, nil, nil, nil, nil, nil))
+	a.Children[0].OpenChildren()
+	tr.Items[1].OpenChildren()
+
+	want := []string{
+		"- a",
+		"|-- a1",
+		"| `-  deep",
+		"`-  a2",
+		"- b",
+		"`-  b1",
+		"  c",
+	}
+	if got := strings.Split(tr.View(), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	tr.Guides = false
+	tr.IndentWidth = 4
+	tr.Glyphs = UnicodeGlyphs
+	tr.ClosedChildrenSymbol = ">"
+	tr.Items[1].CloseChildren()
+	lines := strings.Split(tr.View(), "\n")
+	if lines[0] != "▾ a" || lines[1] != "    ▾ a1" || lines[2] != "          deep" || lines[4] != "> b" {
+		t.Fatalf("indent and overrides not applied:\n%s", strings.Join(lines, "\n"))
+	}
+}
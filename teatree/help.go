@@ -0,0 +1,51 @@
+package teatree
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// ShortHelp returns the bindings for the one line help, for help.KeyMap. While an input has
+// focus, only the keys that close it are listed.
+func (t *Tree) ShortHelp() []key.Binding {
+	t.updateKeys()
+	k := t.KeyMap
+	switch {
+	case t.search.typing:
+		return []key.Binding{k.AcceptSearch, k.CancelSearch}
+	case t.Renaming():
+		return []key.Binding{k.AcceptRename, k.CancelRename}
+	}
+	return []key.Binding{k.Up, k.Down, k.Open, k.Back, k.Select, k.Search, k.Help}
+}
+
+// FullHelp returns every binding, grouped into columns, for help.KeyMap.
+func (t *Tree) FullHelp() [][]key.Binding {
+	t.updateKeys()
+	k := t.KeyMap
+	return [][]key.Binding{
+		{k.Up, k.Down, k.PageUp, k.PageDown, k.GoToTop, k.GoToLast, k.ScrollLeft, k.ScrollRight},
+		{k.Open, k.Back, k.Space, k.Select, k.Check, k.Sort},
+		{k.Search, k.NextMatch, k.PrevMatch, k.CancelSearch},
+		{k.ExpandAll, k.CollapseAll, k.OpenFold, k.CloseFold, k.OpenFoldAll},
+		{k.Rename, k.Cut, k.PasteAfter, k.PasteInside, k.Undo, k.Redo},
+		{k.Help},
+	}
+}
+
+// ToggleHelp switches between the one line help and the full help.
+func (t *Tree) ToggleHelp() {
+	t.help.ShowAll = !t.help.ShowAll
+}
+
+// helpView draws the help shown below the tree, for HelpKeys if they are set, or else for
+// the tree's own bindings.
+func (t *Tree) helpView() string {
+	var keys help.KeyMap = t
+	if t.HelpKeys != nil {
+		keys = t.HelpKeys
+	}
+	t.help.Width = t.innerWidth()
+	t.help.Styles = t.Styles.Help
+	return t.help.View(keys)
+}
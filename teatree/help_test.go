@@ -0,0 +1,44 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelp(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Update(keyMsg("?"))
+	if strings.Contains(tr.View(), "help") {
+		t.Fatal("help should only be drawn when ShowHelp is set")
+	}
+
+	tr.ShowHelp = true
+	lines := strings.Split(tr.View(), "\n")
+	if last := lines[len(lines)-1]; !strings.Contains(last, "more help") || !strings.Contains(last, "search") {
+		t.Fatalf("short help missing: %q", last)
+	}
+	if last := lines[len(lines)-1]; !strings.Contains(last, "up • ") {
+		t.Fatalf("short help should separate the bindings: %q", last)
+	}
+	short := tr.viewHeight()
+
+	tr.Update(keyMsg("?"))
+	view := tr.View()
+	if !strings.Contains(view, "less help") || !strings.Contains(view, "page down") {
+		t.Fatalf("full help missing:\n%s", view)
+	}
+	if tr.viewHeight() >= short {
+		t.Fatalf("full help should take rows from the tree: %d rows, was %d", tr.viewHeight(), short)
+	}
+	if got := len(strings.Split(view, "\n")); got > tr.Height {
+		t.Fatalf("view is %d lines, taller than the tree's height of %d", got, tr.Height)
+	}
+
+	// While searching, the help lists the keys that close the input
+	tr.Update(keyMsg("?"))
+	tr.Update(keyMsg("/"))
+	lines = strings.Split(tr.View(), "\n")
+	if last := lines[len(lines)-1]; !strings.Contains(last, "apply search") || strings.Contains(last, "more help") {
+		t.Fatalf("search help: got %q", last)
+	}
+}
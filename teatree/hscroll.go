@@ -0,0 +1,86 @@
+package teatree
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+// DefaultEllipsis marks where a row has been cut short to fit the tree's width.
+const DefaultEllipsis = "…"
+
+// hscrollStep is how many cells the ScrollLeft and ScrollRight bindings move the view.
+const hscrollStep = 4
+
+// skipCells drops the first n cells of s, which must be plain text. It returns what is left
+// of s, and how many cells are still to be dropped once s is used up. A wide rune that is cut
+// in half is replaced by a space.
+func skipCells(s string, n int) (string, int) {
+	for x, r := range s {
+		if n <= 0 {
+			return s[x:], 0
+		}
+		w := runewidth.RuneWidth(r)
+		if w > n {
+			return strings.Repeat(" ", w-n) + s[x+len(string(r)):], 0
+		}
+		n -= w
+	}
+	return "", n
+}
+
+// clip cuts s, which may contain escape codes, to the tree's width, ending it with the
+// Ellipsis if anything was lost.
+func (t *Tree) clip(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	return truncate.StringWithTail(s, uint(width), t.Ellipsis)
+}
+
+// rowWidth is the number of cells the tree part of a row takes before it is scrolled or cut.
+func (t *Tree) rowWidth(r row) int {
+	ti := r.item
+	width := lipgloss.Width(t.indent(r)+t.chevron(ti)) + lipgloss.Width(ti.Icon()) + 1 + lipgloss.Width(ti.Name)
+	if box := t.checkbox(ti); box != "" {
+		width += lipgloss.Width(box) + 1
+	}
+	return width
+}
+
+// ScrollLeft moves the view n cells towards the start of the rows.
+func (t *Tree) ScrollLeft(n int) {
+	t.Viewleft = max(t.Viewleft-n, 0)
+}
+
+// ScrollRight moves the view n cells along the rows, to show deep or long labels. It stops
+// once the end of the longest row on screen is in view.
+func (t *Tree) ScrollRight(n int) {
+	width := t.innerWidth()
+	if len(t.Columns) > 0 {
+		width = t.layout.label
+	}
+	if width <= 0 {
+		return
+	}
+	rows := t.visibleRows()
+	end := len(rows)
+	if height := t.viewHeight(); height > 0 {
+		end = min(t.Viewtop+height, end)
+	}
+	longest := 0
+	for _, r := range rows[min(t.Viewtop, end):end] {
+		longest = max(longest, t.rowWidth(r))
+	}
+	t.Viewleft = min(t.Viewleft+n, max(longest-width, 0))
+}
+
+// activeLabel draws the status line showing the whole name of the active item.
+func (t *Tree) activeLabel() string {
+	if t.ActiveItem == nil {
+		return ""
+	}
+	return t.Styles.Status.Render(t.clip(strings.Join(t.ActiveItem.GetPath(), "/"), t.innerWidth()))
+}
@@ -0,0 +1,73 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSkipCells(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		n    int
+		want string
+		left int
+	}{
+		{"abc", 1, "bc", 0},
+		{"ab", 3, "", 1},
+		{"日本", 1, " 本", 0},
+		{"日本", 2, "本", 0},
+	} {
+		got, left := skipCells(tc.s, tc.n)
+		if got != tc.want || left != tc.left {
+			t.Fatalf("skipCells(%q, %d) = %q, %d", tc.s, tc.n, got, left)
+		}
+	}
+}
+
+func TestTruncateAndScroll(t *testing.T) {
+	tr := New().(*Tree)
+	deep := NewItem("folder", true, nil, nil, nil, nil, nil, nil, nil)
+	tr.AddChildren(deep)
+	deep.AddChildren(NewItem("a very long name with 日本語 in it", false, nil, nil, nil, nil, nil, nil, nil))
+	deep.OpenChildren()
+	tr.ShowActiveLabel = true
+	tr.Update(tea.WindowSizeMsg{Width: 16, Height: 10})
+	tr.Update(keyMsg("j"))
+
+	lines := strings.Split(tr.View(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected two rows and a status line, got %q", lines)
+	}
+	for _, line := range lines {
+		if lipgloss.Width(line) > 16 {
+			t.Fatalf("line is %d wide: %q", lipgloss.Width(line), line)
+		}
+	}
+	if !strings.HasSuffix(lines[1], DefaultEllipsis) {
+		t.Fatalf("expected an ellipsis on %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "folder/a very") {
+		t.Fatalf("bad status line %q", lines[2])
+	}
+	if tr.viewHeight() != 9 {
+		t.Fatal("the status line should take a line from the rows")
+	}
+
+	// Scrolling right shows the end of the name, and stops there
+	for x := 0; x < 20; x++ {
+		tr.Update(keyMsg("L"))
+	}
+	lines = strings.Split(tr.View(), "\n")
+	if !strings.HasSuffix(lines[1], "in it") || tr.Viewleft != tr.rowWidth(tr.visibleRows()[1])-16 {
+		t.Fatalf("scrolled to %d: %q", tr.Viewleft, lines[1])
+	}
+	for x := 0; x < 20; x++ {
+		tr.Update(keyMsg("H"))
+	}
+	if tr.Viewleft != 0 {
+		t.Fatalf("expected to scroll back to the start, got %d", tr.Viewleft)
+	}
+}
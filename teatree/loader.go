@@ -0,0 +1,224 @@
+package teatree
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LoadFunc fetches the children of an item. It is run inside a tea.Cmd, off the Update
+// goroutine, so it is free to block. It must not touch the tree or ti itself; it should
+// build the new children and return them. ctx is cancelled if the item is collapsed before
+// the load finishes. Hosts that already fetch with their own commands can use LoadCmd
+// instead.
+type LoadFunc func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error)
+
+// LoadCmd returns a command that fetches the children of an item. The command, or one it
+// leads to, must produce a LoadedMsg for ti, which reaches the tree through the host's Update
+// like any other message. A command can't be stopped, so if the item is collapsed before the
+// LoadedMsg arrives, it is dropped.
+type LoadCmd func(ti *TreeItem) tea.Cmd
+
+// LoadedMsg carries the result of a LoadCmd back to the tree.
+type LoadedMsg struct {
+	Item     *TreeItem
+	Children []*TreeItem
+	Err      error
+	id       int // The load the result is for, see stampLoad
+}
+
+const LoadingLabel = "loading…"
+
+// loadedMsg carries the result of a LoadFunc back to the Update goroutine.
+type loadedMsg struct {
+	item     *TreeItem
+	id       int
+	children []*TreeItem
+	err      error
+}
+
+// Loading reports whether the item's children are being fetched in the background.
+func (ti *TreeItem) Loading() bool {
+	return ti.cancelLoad != nil
+}
+
+// lazy reports whether the item's children are fetched by a LoadFunc or LoadCmd.
+func (ti *TreeItem) lazy() bool {
+	return ti.LoadFunc != nil || ti.LoadCmd != nil
+}
+
+// fetch runs the item's loader and waits for the result, for use outside of the Update
+// loop.
+func (ti *TreeItem) fetch() ([]*TreeItem, error) {
+	if ti.LoadFunc != nil {
+		return ti.LoadFunc(context.Background(), ti)
+	}
+	var msg tea.Msg
+	if cmd := ti.LoadCmd(ti); cmd != nil {
+		msg = cmd()
+	}
+	if msg, ok := msg.(LoadedMsg); ok {
+		return msg.Children, msg.Err
+	}
+	return nil, fmt.Errorf("teatree: the LoadCmd of %q produced %T, not a LoadedMsg", ti.Name, msg)
+}
+
+// startLoad replaces the item's children with a placeholder and queues a command on the
+// tree that runs the item's LoadFunc.
+func (ti *TreeItem) startLoad() {
+	t := ti.parentTree
+	if t == nil {
+		// Not attached to a tree, so there's no Update loop to deliver the result to
+		ti.finishLoad(ti.fetch())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ti.cancelLoad = cancel
+	t.loadID++
+	ti.loadID = t.loadID
+
+	// Loads aren't edits, so they are kept out of the undo history
+	clearChildren(ti)
+	insertItems(ti, 0, []*TreeItem{{
+		Name:        LoadingLabel,
+		placeholder: true,
+		icon:        func(*TreeItem) string { return t.spinner.View() },
+	}})
+	ti.invalidate()
+
+	if ti.LoadCmd != nil {
+		t.queue(stampLoad(ti.LoadCmd(ti), ti.loadID))
+	} else {
+		item, id, load := ti, ti.loadID, ti.LoadFunc
+		t.queue(func() tea.Msg {
+			children, err := load(ctx, item)
+			return loadedMsg{item: item, id: id, children: children, err: err}
+		})
+	}
+	if t.loads == 0 {
+		t.queue(t.spinner.Tick)
+	}
+	t.loads++
+}
+
+// cancelLoading abandons a load that is still running, removing its placeholder.
+func (ti *TreeItem) cancelLoading() {
+	if ti.cancelLoad == nil {
+		return
+	}
+	ti.cancelLoad()
+	ti.cancelLoad = nil
+	// Removing the placeholder moves a cursor that was on it up to ti
+	clearChildren(ti)
+	ti.invalidate()
+	if t := ti.parentTree; t != nil {
+		t.loads--
+	}
+}
+
+// clearChildren detaches every child of ti, without recording it in the undo history.
+func clearChildren(ti *TreeItem) {
+	for len(ti.Children) > 0 {
+		removeItem(ti, ti.Children[len(ti.Children)-1])
+	}
+}
+
+// finishLoad installs the result of a load. An error is shown as a row in place of the
+// children, and the load is retried the next time the item is opened.
+func (ti *TreeItem) finishLoad(children []*TreeItem, err error) {
+	clearChildren(ti)
+	ti.invalidate()
+	if err != nil {
+		insertItems(ti, 0, []*TreeItem{{
+			Name:        err.Error(),
+			placeholder: true,
+			labelStyle: func(e *TreeItem) lipgloss.Style {
+				if e.parentTree == nil {
+					return lipgloss.NewStyle()
+				}
+				return e.parentTree.Styles.LoadError
+			},
+		}})
+		ti.loaded = false
+		return
+	}
+	ti.loaded = true
+	insertItems(ti, 0, children)
+}
+
+// updateLoaded handles the result of a background load. Results for loads that have since
+// been cancelled or restarted are dropped.
+func (t *Tree) updateLoaded(msg loadedMsg) {
+	ti := msg.item
+	if ti.cancelLoad == nil || ti.loadID != msg.id {
+		return
+	}
+	ti.cancelLoad()
+	ti.cancelLoad = nil
+	t.loads--
+
+	onPlaceholder := t.ActiveItem != nil && t.ActiveItem.parent == ti
+	ti.finishLoad(msg.children, msg.err)
+	t.reportError(msg.err)
+	if onPlaceholder {
+		t.SetActive(ti)
+	}
+	t.applyPending()
+	t.scrollToActive()
+}
+
+// stampLoad wraps the command returned by a LoadCmd, so that the LoadedMsg it produces, either
+// directly or from a batch, carries the id of the load that ran it.
+func stampLoad(cmd tea.Cmd, id int) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case LoadedMsg:
+			msg.id = id
+			return msg
+		case tea.BatchMsg:
+			for x := range msg {
+				msg[x] = stampLoad(msg[x], id)
+			}
+			return msg
+		default:
+			return msg
+		}
+	}
+}
+
+// updateLoadedCmd handles the result of a LoadCmd. A LoadedMsg that stampLoad didn't see,
+// such as one from a tea.Sequence, is taken as the result of whatever load is running on the
+// item.
+func (t *Tree) updateLoadedCmd(msg LoadedMsg) {
+	if msg.Item == nil || msg.Item.parentTree != t {
+		return
+	}
+	id := msg.id
+	if id == 0 {
+		id = msg.Item.loadID
+	}
+	t.updateLoaded(loadedMsg{item: msg.Item, id: id, children: msg.Children, err: msg.Err})
+}
+
+// updateSpinner keeps the loading placeholders animated for as long as a load is running.
+func (t *Tree) updateSpinner(msg spinner.TickMsg) tea.Cmd {
+	if t.loads == 0 {
+		return nil
+	}
+	var cmd tea.Cmd
+	t.spinner, cmd = t.spinner.Update(msg)
+	return cmd
+}
+
+// queue adds a command to be returned from the next call to Update. It lets code that is
+// called from Update, such as ToggleChildren, start background work.
+func (t *Tree) queue(cmd tea.Cmd) {
+	t.cmds = append(t.cmds, cmd)
+}
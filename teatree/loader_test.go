@@ -0,0 +1,165 @@
+package teatree
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// run executes cmd, and any batch it expands to, returning every message produced apart
+// from spinner ticks.
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, c := range msg {
+			msgs = append(msgs, run(c)...)
+		}
+		return msgs
+	case spinner.TickMsg, nil:
+		return nil
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+func newLazyTree(load LoadFunc) (*Tree, *TreeItem) {
+	tr := New().(*Tree)
+	tr.Height = 10
+	dir := NewItem("dir", true, nil, nil, nil, nil, nil, nil, nil)
+	dir.LoadFunc = load
+	tr.AddChildren(dir)
+	return tr, dir
+}
+
+func TestAsyncLoad(t *testing.T) {
+	calls := 0
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		calls++
+		return []*TreeItem{NewItem("file", false, nil, nil, nil, nil, nil, nil, nil)}, nil
+	})
+
+	_, cmd := tr.Update(keyMsg(" "))
+	if !dir.Loading() || len(dir.Children) != 1 || dir.Children[0].Name != LoadingLabel {
+		t.Fatal("expected a loading placeholder while the load runs")
+	}
+	if calls != 0 {
+		t.Fatal("loader ran inside Update")
+	}
+	for _, msg := range run(cmd) {
+		tr.Update(msg)
+	}
+	if dir.Loading() || names(dir.Children) != "file" {
+		t.Fatalf("children not installed: %s", names(dir.Children))
+	}
+
+	// Cached after the first load
+	tr.Update(keyMsg(" "))
+	_, cmd = tr.Update(keyMsg(" "))
+	for _, msg := range run(cmd) {
+		if _, ok := msg.(loadedMsg); ok {
+			t.Fatal("loaded children should not be fetched again")
+		}
+	}
+	if calls != 1 {
+		t.Fatal("loaded children should not be fetched again")
+	}
+}
+
+func TestAsyncLoadCancel(t *testing.T) {
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, cmd := tr.Update(keyMsg(" "))
+	tr.Update(keyMsg("j"))
+	if tr.ActiveItem.Name != LoadingLabel {
+		t.Fatalf("cursor should be on the placeholder, got %q", tr.ActiveItem.Name)
+	}
+	dir.ToggleChildren() // collapsing cancels the context, so the loader returns
+	if dir.Loading() || len(dir.Children) != 0 || tr.ActiveItem != dir {
+		t.Fatal("collapsing should drop the load and its placeholder")
+	}
+	for _, msg := range run(cmd) {
+		tr.Update(msg)
+	}
+	if len(dir.Children) != 0 {
+		t.Fatal("result of a cancelled load was installed")
+	}
+}
+
+func TestAsyncLoadError(t *testing.T) {
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return nil, errors.New("permission denied")
+	})
+
+	_, cmd := tr.Update(keyMsg(" "))
+	for _, msg := range run(cmd) {
+		tr.Update(msg)
+	}
+	if names(dir.Children) != "permission denied" || !dir.Children[0].placeholder {
+		t.Fatalf("expected an error row, got %s", names(dir.Children))
+	}
+}
+
+func TestAsyncLoadCmd(t *testing.T) {
+	tr, dir := newLazyTree(nil)
+	dir.LoadCmd = func(ti *TreeItem) tea.Cmd {
+		return func() tea.Msg {
+			return LoadedMsg{Item: ti, Children: []*TreeItem{NewItem("file", false, nil, nil, nil, nil, nil, nil, nil)}}
+		}
+	}
+
+	_, cmd := tr.Update(keyMsg(" "))
+	if !dir.Loading() {
+		t.Fatal("expected a loading placeholder while the command runs")
+	}
+	msgs := run(cmd)
+	for _, msg := range msgs {
+		tr.Update(msg)
+	}
+	if dir.Loading() || names(dir.Children) != "file" {
+		t.Fatalf("children not installed: %s", names(dir.Children))
+	}
+
+	// A result arriving after the item was collapsed is dropped
+	dir.Refresh()
+	dir.ToggleChildren()
+	dir.ToggleChildren()
+	for _, msg := range msgs {
+		tr.Update(msg)
+	}
+	if len(dir.Children) != 0 {
+		t.Fatal("result of a cancelled load was installed")
+	}
+
+	// and so is one arriving after the item was opened again, which started a new load
+	dir.ToggleChildren()
+	for _, msg := range msgs {
+		tr.Update(msg)
+	}
+	if !dir.Loading() {
+		t.Fatal("result of a superseded load was installed")
+	}
+}
+
+func TestLoadDetachesChildren(t *testing.T) {
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return nil, nil
+	})
+	old := NewItem("old", false, nil, nil, nil, nil, nil, nil, nil)
+	old.ID = "old"
+	dir.AddChildren(old)
+	dir.Open = false
+	dir.ToggleChildren()
+	if old.GetParent() != nil || tr.FindByID("old") != nil {
+		t.Fatal("children replaced by a load should be detached")
+	}
+}
@@ -0,0 +1,85 @@
+package teatree
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateMouse handles clicks and the scroll wheel. Coordinates are taken relative to
+// OriginX and OriginY, so a host that draws the tree somewhere other than the top left of
+// the screen needs to set those.
+func (t *Tree) updateMouse(msg tea.MouseMsg) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		t.scrollBy(-1)
+		return
+	case tea.MouseButtonWheelDown:
+		t.scrollBy(1)
+		return
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return
+		}
+	default:
+		return
+	}
+
+	ti := t.itemAtLine(msg.Y - t.OriginY)
+	if ti == nil {
+		return
+	}
+
+	// Work out which part of the row was hit, using the same layout as renderRow
+	x := msg.X - t.OriginX - t.frameLeft() + t.Viewleft
+	r := t.rows[t.rowOf(ti)]
+	chevronStart := lipgloss.Width(t.indent(r))
+	chevronEnd := chevronStart + lipgloss.Width(t.chevron(ti))
+	boxEnd := chevronEnd + lipgloss.Width(t.checkbox(ti))
+	switch {
+	case ti.CanHaveChildren && x >= chevronStart && x < chevronEnd:
+		ti.ToggleChildren()
+		// Closing an ancestor of the cursor hides it
+		t.cursorToVisible()
+	case x >= chevronEnd && x < boxEnd:
+		t.ToggleChecked(ti)
+	default:
+		t.SetActive(ti)
+	}
+	t.scrollToActive()
+}
+
+// itemAtLine returns the item drawn on the given line of the tree's view, or nil if the line
+// is part of the header or past the last item.
+func (t *Tree) itemAtLine(line int) *TreeItem {
+	row := line - t.headerHeight()
+	if row < 0 || (t.viewHeight() > 0 && row >= t.viewHeight()) {
+		return nil
+	}
+	rows := t.visibleRows()
+	if t.Viewtop+row >= len(rows) {
+		return nil
+	}
+	return rows[t.Viewtop+row].item
+}
+
+// scrollBy moves the view n lines down, or up if n is negative, without moving past either
+// end. The cursor is dragged along if it would otherwise leave the screen.
+func (t *Tree) scrollBy(n int) {
+	rows := t.visibleRows()
+	height := t.viewHeight()
+	if height <= 0 {
+		return
+	}
+	t.Viewtop = min(max(t.Viewtop+n, 0), max(len(rows)-height, 0))
+
+	idx := t.rowOf(t.ActiveItem)
+	if idx < 0 {
+		return
+	}
+	if idx < t.Viewtop {
+		t.SetActive(rows[t.Viewtop].item)
+	} else if idx >= t.Viewtop+height {
+		t.SetActive(rows[t.Viewtop+height-1].item)
+	}
+	t.scrollToActive()
+}
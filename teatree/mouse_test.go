@@ -0,0 +1,65 @@
+package teatree
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func TestMouse(t *testing.T) {
+	tr := newTestTree(3)
+	tr.OriginY = 2
+
+	tr.Update(click(6, 3))
+	if tr.ActiveItem.Name != "b" || tr.ActiveLine != 1 {
+		t.Fatalf("click: got %q on line %d, want b on line 1", tr.ActiveItem.Name, tr.ActiveLine)
+	}
+
+	// The chevron of the first row toggles it without moving the cursor
+	tr.Update(click(0, 2))
+	if !tr.Items[0].Open || tr.ActiveItem.Name != "b" {
+		t.Fatalf("chevron click: open %v, active %q", tr.Items[0].Open, tr.ActiveItem.Name)
+	}
+	if tr.ActiveLine != 2 {
+		t.Fatalf("cursor line not updated after expanding above it: %d", tr.ActiveLine)
+	}
+
+	// Clicks outside the rows are ignored
+	tr.Update(click(6, 0))
+	tr.Update(click(6, 9))
+	if tr.ActiveItem.Name != "b" {
+		t.Fatalf("click outside the tree moved the cursor to %q", tr.ActiveItem.Name)
+	}
+
+	// The wheel scrolls, dragging the cursor along once it reaches the edge
+	for x := 0; x < 5; x++ {
+		tr.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown})
+	}
+	if tr.Viewtop != 2 {
+		t.Fatalf("wheel down should stop at the last page, Viewtop %d", tr.Viewtop)
+	}
+	tr.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp})
+	tr.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp})
+	if tr.Viewtop != 0 || tr.ActiveItem.Name != "a2" || tr.ActiveLine != 2 {
+		t.Fatalf("wheel up: top %d, active %q on line %d", tr.Viewtop, tr.ActiveItem.Name, tr.ActiveLine)
+	}
+}
+
+func TestMouseCollapseAncestor(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Items[0].OpenChildren()
+	tr.Update(keyMsg("j"))
+
+	// Closing a while the cursor is on a1 brings the cursor up to a
+	tr.Update(click(0, 0))
+	if tr.Items[0].Open || tr.ActiveItem.Name != "a" {
+		t.Fatalf("chevron click: open %v, active %q", tr.Items[0].Open, tr.ActiveItem.Name)
+	}
+	if !tr.ActiveShown() || tr.ActiveLine != 0 {
+		t.Fatalf("cursor should be drawn on line 0, got %d", tr.ActiveLine)
+	}
+}
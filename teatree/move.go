@@ -0,0 +1,130 @@
+package teatree
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrDropRefused is returned by Paste when the tree's CanDrop func turns the move down.
+	ErrDropRefused = errors.New("teatree: the items can't be moved there")
+	// ErrNotLoaded is returned by Paste when the new parent's children are still to be
+	// loaded, as they would replace the pasted items.
+	ErrNotLoaded = errors.New("teatree: can't paste into an item that hasn't been loaded")
+	// ErrSorted is returned by Paste when items would only be reordered among their siblings,
+	// but a sort order decides where they go.
+	ErrSorted = errors.New("teatree: the items are sorted, so they can't be reordered")
+)
+
+// DropFunc reports whether item may be moved into parent. parent is nil for the top level
+// of the tree.
+type DropFunc func(item, parent *TreeItem) bool
+
+// Cut marks items to be moved by the next Paste: the checked items, if there are any, or
+// else the active item. Cutting the same items again unmarks them. It does nothing unless
+// the tree's AllowMoves is set.
+func (t *Tree) Cut() {
+	if !t.AllowMoves {
+		return
+	}
+	var items []*TreeItem
+	if t.SelectionMode != SelectSingle {
+		for _, ti := range t.Checked() {
+			// Checking a parent in SelectCascade mode checks its children too, but they
+			// move with it
+			if par, ok := ti.GetParent().(*TreeItem); ok && par.Checked {
+				continue
+			}
+			items = append(items, ti)
+		}
+	}
+	if len(items) == 0 && t.ActiveItem != nil && !t.ActiveItem.placeholder {
+		items = []*TreeItem{t.ActiveItem}
+	}
+	if slices.Equal(items, t.cut) {
+		items = nil
+	}
+	t.cut = items
+}
+
+// CancelCut unmarks the items marked by Cut.
+func (t *Tree) CancelCut() {
+	t.cut = nil
+}
+
+// CutItems returns the items waiting to be moved by Paste.
+func (t *Tree) CutItems() []*TreeItem {
+	return t.cut
+}
+
+// marked reports whether ti is waiting to be moved.
+func (t *Tree) marked(ti *TreeItem) bool {
+	return slices.Contains(t.cut, ti)
+}
+
+// Paste moves the items marked by Cut. With inside set they become the last children of the
+// active item, otherwise they are put after it, under the same parent. Nothing is moved
+// unless every item can go there; the marks are kept so that another place can be tried. A
+// MovedMsg is sent for each item that is moved.
+func (t *Tree) Paste(inside bool) error {
+	// Items that have been removed from the tree since they were cut are left behind
+	items := slices.DeleteFunc(t.cut, func(ti *TreeItem) bool {
+		return ti.parentTree != t
+	})
+	t.cut = items
+	if len(items) == 0 {
+		t.cut = nil
+		return nil
+	}
+
+	var parent ItemHolder = t
+	index := len(t.Items)
+	if at := t.ActiveItem; at != nil {
+		if inside {
+			if at.placeholder || (at.lazy() && !at.loaded) {
+				return ErrNotLoaded
+			}
+			parent, index = at, len(at.Children)
+		} else {
+			parent = at.GetParent()
+			index = slices.Index(*childList(parent), at) + 1
+		}
+	}
+	if lessFor(parent) != nil && !slices.ContainsFunc(items, func(ti *TreeItem) bool {
+		return ti.GetParent() != parent
+	}) {
+		return ErrSorted
+	}
+	target, _ := parent.(*TreeItem)
+	for _, ti := range items {
+		if target != nil && target.isWithin(ti) {
+			return ErrMoveIntoSelf
+		}
+		if t.CanDrop != nil && !t.CanDrop(ti, target) {
+			return ErrDropRefused
+		}
+	}
+
+	var moves []MovedMsg
+	t.grouped(describe("move", items), func() {
+		for _, ti := range items {
+			old := ti.GetParent()
+			// Taking an item out from before the insertion point shifts everything after it up
+			if old == parent && slices.Index(*childList(old), ti) < index {
+				index--
+			}
+			t.MoveItem(ti, parent, index)
+			index++
+			moves = append(moves, MovedMsg{Tree: t, Item: ti, Path: ti.GetPath(), OldParent: old, NewParent: parent})
+		}
+	})
+	t.cut = nil
+
+	t.reveal(items[0])
+	t.SetActive(items[0])
+	t.scrollToActive()
+	for _, msg := range moves {
+		t.emit(msg)
+	}
+	return nil
+}
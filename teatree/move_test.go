@@ -0,0 +1,86 @@
+package teatree
+
+import (
+	"testing"
+)
+
+func TestCutPaste(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Update(keyMsg("x"))
+	if len(tr.CutItems()) != 0 {
+		t.Fatal("cut should be off unless AllowMoves is set")
+	}
+	tr.AllowMoves = true
+
+	// Move a after b
+	tr.Update(keyMsg("x"))
+	tr.Update(keyMsg("j"))
+	_, cmd := tr.Update(keyMsg("p"))
+	if got := names(tr.Items); got != "b,a,c" {
+		t.Fatalf("paste after: got %s", got)
+	}
+	if tr.ActiveItem.Name != "a" {
+		t.Fatalf("cursor should follow the pasted item, got %q", tr.ActiveItem.Name)
+	}
+	var moved *MovedMsg
+	for _, msg := range run(cmd) {
+		if m, ok := msg.(MovedMsg); ok {
+			moved = &m
+		}
+	}
+	if moved == nil || moved.Item.Name != "a" || moved.OldParent != ItemHolder(tr) || moved.NewParent != ItemHolder(tr) {
+		t.Fatalf("expected MovedMsg for a, got %#v", moved)
+	}
+
+	// Move c inside b
+	c, b := tr.Items[2], tr.Items[0]
+	tr.SetActive(c)
+	tr.Update(keyMsg("x"))
+	tr.SetActive(b)
+	tr.Update(keyMsg("P"))
+	if got := names(b.Children); got != "b1,c" || c.GetParent() != ItemHolder(b) {
+		t.Fatalf("paste inside: got %s", got)
+	}
+	if got := names(tr.visibleItems()); got != "b,b1,c,a" {
+		t.Fatalf("pasted item should be revealed: got %s", got)
+	}
+
+	// b can't go inside its own child
+	tr.SetActive(b)
+	tr.Cut()
+	tr.SetActive(c)
+	if err := tr.Paste(true); err != ErrMoveIntoSelf {
+		t.Fatalf("expected ErrMoveIntoSelf, got %v", err)
+	}
+	if len(tr.CutItems()) != 1 {
+		t.Fatal("a refused paste should keep the marks")
+	}
+}
+
+func TestCutCheckedCanDrop(t *testing.T) {
+	tr := newTestTree(10)
+	tr.AllowMoves = true
+	tr.SelectionMode = SelectMulti
+	a, b, c := tr.Items[0], tr.Items[1], tr.Items[2]
+	tr.SetChecked(a.Children[0], true)
+	tr.SetChecked(b.Children[0], true)
+	tr.Cut()
+	if got := names(tr.CutItems()); got != "a1,b1" {
+		t.Fatalf("cut should take the checked items: got %s", got)
+	}
+
+	tr.CanDrop = func(item, parent *TreeItem) bool { return parent != nil }
+	tr.SetActive(c)
+	if err := tr.Paste(false); err != ErrDropRefused {
+		t.Fatalf("expected ErrDropRefused, got %v", err)
+	}
+	if err := tr.Paste(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(c.Children); got != "a1,b1" {
+		t.Fatalf("got %s inside c", got)
+	}
+	if got := names(a.Children) + ";" + names(b.Children); got != "a2;" {
+		t.Fatalf("items left behind: got %s", got)
+	}
+}
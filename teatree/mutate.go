@@ -0,0 +1,296 @@
+package teatree
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrMoveIntoSelf is returned by MoveItem when the new parent is the item itself, or one of
+// its descendants.
+var ErrMoveIntoSelf = errors.New("teatree: can't move an item below itself")
+
+// holderTree returns the tree that h is part of, or nil if it isn't attached to one.
+func holderTree(h ItemHolder) *Tree {
+	switch h := h.(type) {
+	case *Tree:
+		return h
+	case *TreeItem:
+		return h.parentTree
+	}
+	return nil
+}
+
+// childList returns the slice that holds the children of h.
+func childList(h ItemHolder) *[]*TreeItem {
+	switch h := h.(type) {
+	case *Tree:
+		return &h.Items
+	case *TreeItem:
+		return &h.Children
+	}
+	return nil
+}
+
+// keepCursor runs fn, which changes the structure of the tree, and then puts the cursor back
+// on the same line of the screen, or as close to it as the new rows allow. It does nothing
+// more than run fn when t is nil.
+func (t *Tree) keepCursor(fn func()) {
+	if t == nil {
+		fn()
+		return
+	}
+	line := t.rowOf(t.ActiveItem) - t.Viewtop
+	fn()
+	t.Invalidate()
+	if t.ActiveItem == nil && len(t.Items) > 0 {
+		t.SetActive(t.Items[0])
+	}
+	if idx := t.rowOf(t.ActiveItem); idx >= 0 && line >= 0 {
+		t.Viewtop = max(idx-line, 0)
+	}
+	t.scrollToActive()
+}
+
+// insertItems puts children into h at index, which is clamped to the length of the list. If
+// h has a sort order, that decides where they go instead.
+func insertItems(h ItemHolder, index int, children []*TreeItem) {
+	t := holderTree(h)
+	list := childList(h)
+	index = min(max(index, 0), len(*list))
+	*list = slices.Insert(*list, index, children...)
+
+	par, isItem := h.(*TreeItem)
+	if isItem {
+		par.CanHaveChildren = true
+	}
+	for _, child := range children {
+		child.parent = h
+		child.setTree(t)
+		countChecked(child)
+		addChecked(h, child.checkedCount())
+		// New children of a checked parent are part of the checked subtree
+		if isItem && par.Checked && t != nil && t.SelectionMode == SelectCascade {
+			t.SetChecked(child, true)
+		}
+		child.stamp()
+		sortTree(child)
+	}
+	sortChildren(h)
+}
+
+// removeItem takes child out of h, detaching it from the tree. If the cursor was on child,
+// or below it, it moves to the next sibling, then the previous sibling, and finally the
+// parent. It reports whether child was found.
+func removeItem(h ItemHolder, child *TreeItem) bool {
+	list := childList(h)
+	x := slices.Index(*list, child)
+	if x < 0 {
+		return false
+	}
+
+	if t := holderTree(h); t != nil && t.ActiveItem != nil && t.ActiveItem.isWithin(child) {
+		switch par, _ := h.(*TreeItem); {
+		case x+1 < len(*list):
+			t.SetActive((*list)[x+1])
+		case x > 0:
+			t.SetActive((*list)[x-1])
+		case par != nil:
+			t.SetActive(par)
+		default:
+			t.SetActive(nil)
+		}
+	}
+
+	*list = slices.Delete(*list, x, x+1)
+	addChecked(h, -child.checkedCount())
+	forEachItem([]*TreeItem{child}, func(ti *TreeItem) {
+		ti.cancelLoading()
+	})
+	child.parent = nil
+	child.setTree(nil)
+	return true
+}
+
+// isWithin reports whether ti is anc, or one of its descendants.
+func (ti *TreeItem) isWithin(anc *TreeItem) bool {
+	for item := ti; item != nil; item, _ = item.GetParent().(*TreeItem) {
+		if item == anc {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceChildren swaps every child of h for children. If the cursor was below h, it goes
+// to the new item with the same path, or failing that to h itself.
+func replaceChildren(h ItemHolder, children []*TreeItem) {
+	t := holderTree(h)
+	var activePath []string
+	if t != nil && t.ActiveItem != nil {
+		activePath = t.ActiveItem.GetPath()
+	}
+	list := childList(h)
+	for len(*list) > 0 {
+		removeItem(h, (*list)[0])
+	}
+	insertItems(h, 0, children)
+
+	// Removing the old children left the cursor on h, or nowhere for the top of the tree
+	if activePath != nil && (t.ActiveItem == nil || t.ActiveItem == h) {
+		if ti := t.itemAt(activePath); ti != nil {
+			t.SetActive(ti)
+		}
+	}
+}
+
+// insertRecorded is insertItems, recorded in the undo history of the tree h is part of.
+func insertRecorded(h ItemHolder, index int, children []*TreeItem) {
+	children = slices.Clone(children)
+	insertItems(h, index, children)
+	holderTree(h).record(describe("add", children), children, func() {
+		for _, child := range children {
+			removeItem(h, child)
+		}
+	}, func() {
+		insertItems(h, index, children)
+	})
+}
+
+// removeRecorded is removeItem, recorded in the undo history of the tree h is part of.
+func removeRecorded(h ItemHolder, child *TreeItem) bool {
+	t := holderTree(h)
+	x := slices.Index(*childList(h), child)
+	if !removeItem(h, child) {
+		return false
+	}
+	t.record(describe("remove", []*TreeItem{child}), []*TreeItem{child}, func() {
+		insertItems(h, x, []*TreeItem{child})
+	}, func() {
+		removeItem(h, child)
+	})
+	return true
+}
+
+// replaceRecorded is replaceChildren, recorded in the undo history of the tree h is part of.
+func replaceRecorded(h ItemHolder, children []*TreeItem) {
+	children = slices.Clone(children)
+	old := slices.Clone(*childList(h))
+	replaceChildren(h, children)
+	desc := "replace the items"
+	if ti, ok := h.(*TreeItem); ok {
+		desc = "replace the children of " + ti.Name
+	}
+	holderTree(h).record(desc, children, func() {
+		replaceChildren(h, old)
+	}, func() {
+		replaceChildren(h, children)
+	})
+}
+
+// RemoveItem takes child out of the top level of the tree. If the cursor was on child, or
+// below it, it moves to a neighbouring item. It reports whether child was found.
+func (t *Tree) RemoveItem(child *TreeItem) bool {
+	var found bool
+	t.keepCursor(func() {
+		found = removeRecorded(t, child)
+	})
+	return found
+}
+
+// InsertAt adds children to the top level of the tree, starting at index.
+func (t *Tree) InsertAt(index int, children ...*TreeItem) ItemHolder {
+	t.keepCursor(func() {
+		insertRecorded(t, index, children)
+	})
+	return t
+}
+
+// ReplaceChildren swaps the top level items of the tree for children. The cursor stays on
+// the item with the same path, if there is one.
+func (t *Tree) ReplaceChildren(children ...*TreeItem) ItemHolder {
+	t.keepCursor(func() {
+		replaceRecorded(t, children)
+	})
+	return t
+}
+
+// MoveItem takes ti from wherever it is in the tree and inserts it into newParent at index.
+// The cursor follows ti if it was on it.
+func (t *Tree) MoveItem(ti *TreeItem, newParent ItemHolder, index int) error {
+	if par, ok := newParent.(*TreeItem); ok && par.isWithin(ti) {
+		return ErrMoveIntoSelf
+	}
+	old, oldIndex := ti.GetParent(), -1
+	if old != nil {
+		oldIndex = slices.Index(*childList(old), ti)
+	}
+	t.moveItem(ti, newParent, index)
+	t.record(describe("move", []*TreeItem{ti}), []*TreeItem{ti}, func() {
+		if old != nil {
+			t.moveItem(ti, old, oldIndex)
+		} else {
+			removeItem(newParent, ti)
+		}
+	}, func() {
+		t.moveItem(ti, newParent, index)
+	})
+	return nil
+}
+
+// moveItem does the work of MoveItem, without checking or recording the move.
+func (t *Tree) moveItem(ti *TreeItem, newParent ItemHolder, index int) {
+	t.keepCursor(func() {
+		wasActive := t.ActiveItem
+		if old := ti.GetParent(); old != nil {
+			removeItem(old, ti)
+		}
+		insertItems(newParent, index, []*TreeItem{ti})
+		if wasActive != nil && wasActive.isWithin(ti) {
+			t.reveal(wasActive)
+			t.SetActive(wasActive)
+		}
+	})
+}
+
+// RemoveItem takes child out of the item's children. If the cursor was on child, or below
+// it, it moves to a neighbouring item. It reports whether child was found.
+func (ti *TreeItem) RemoveItem(child *TreeItem) bool {
+	var found bool
+	ti.parentTree.keepCursor(func() {
+		found = removeRecorded(ti, child)
+	})
+	return found
+}
+
+// InsertAt adds children to the item, starting at index.
+func (ti *TreeItem) InsertAt(index int, children ...*TreeItem) ItemHolder {
+	ti.parentTree.keepCursor(func() {
+		insertRecorded(ti, index, children)
+	})
+	return ti
+}
+
+// ReplaceChildren swaps the children of the item for a new set. The cursor stays on the item
+// with the same path, if there is one, or else moves to this item.
+func (ti *TreeItem) ReplaceChildren(children ...*TreeItem) ItemHolder {
+	ti.parentTree.keepCursor(func() {
+		replaceRecorded(ti, children)
+	})
+	return ti
+}
+
+// MoveItem moves the item to newParent, at index. Both must be part of the same tree, if
+// the item is attached to one.
+func (ti *TreeItem) MoveItem(newParent ItemHolder, index int) error {
+	if t := ti.parentTree; t != nil {
+		return t.MoveItem(ti, newParent, index)
+	}
+	if par, ok := newParent.(*TreeItem); ok && par.isWithin(ti) {
+		return ErrMoveIntoSelf
+	}
+	if old := ti.GetParent(); old != nil {
+		removeItem(old, ti)
+	}
+	insertItems(newParent, index, []*TreeItem{ti})
+	return nil
+}
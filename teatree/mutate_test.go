@@ -0,0 +1,97 @@
+package teatree
+
+import (
+	"testing"
+)
+
+func TestRemoveItem(t *testing.T) {
+	tr := newTestTree(10)
+	a, b, c := tr.Items[0], tr.Items[1], tr.Items[2]
+	a.OpenChildren()
+	a2 := a.Children[1]
+
+	// The cursor goes to the next sibling, then the previous one, then the parent
+	tr.SetActive(a.Children[0])
+	a.RemoveItem(a.Children[0])
+	if tr.ActiveItem != a2 || names(a.Children) != "a2" {
+		t.Fatalf("expected the cursor on a2, got %s", tr.ActiveItem.Name)
+	}
+	a.RemoveItem(a2)
+	if tr.ActiveItem != a || a2.GetParent() != nil || a2.parentTree != nil {
+		t.Fatal("removed item should be detached, with the cursor on its parent")
+	}
+	tr.SetActive(c)
+	tr.RemoveItem(c)
+	if tr.ActiveItem != b || tr.ActiveLine != 1 {
+		t.Fatalf("expected the cursor on b, got %s line %d", tr.ActiveItem.Name, tr.ActiveLine)
+	}
+	if tr.RemoveItem(c) {
+		t.Fatal("c was already removed")
+	}
+	tr.RemoveItem(a)
+	tr.RemoveItem(b)
+	if tr.ActiveItem != nil || tr.CountVisibleItems() != 0 {
+		t.Fatal("tree should be empty")
+	}
+}
+
+func TestInsertAndMove(t *testing.T) {
+	tr := newTestTree(3)
+	a, b, c := tr.Items[0], tr.Items[1], tr.Items[2]
+	tr.SetActive(c)
+	tr.Update(keyMsg("k"))
+	tr.Update(keyMsg("j"))
+	line := tr.ActiveLine
+
+	// Inserting above the cursor leaves it on the same line of the screen
+	tr.InsertAt(0, NewItem("z", false, nil, nil, nil, nil, nil, nil, nil))
+	if names(tr.Items) != "z,a,b,c" || tr.ActiveItem != c || tr.ActiveLine != line {
+		t.Fatalf("insert: %s, cursor %s line %d", names(tr.Items), tr.ActiveItem.Name, tr.ActiveLine)
+	}
+	b.InsertAt(0, NewItem("b0", false, nil, nil, nil, nil, nil, nil, nil))
+	if names(b.Children) != "b0,b1" || b.Children[0].GetParent() != b {
+		t.Fatalf("insert into item: %s", names(b.Children))
+	}
+
+	if err := tr.MoveItem(c, a, 1); err != nil {
+		t.Fatal(err)
+	}
+	if names(tr.Items) != "z,a,b" || names(a.Children) != "a1,c,a2" || c.GetParent() != a {
+		t.Fatalf("move: %s / %s", names(tr.Items), names(a.Children))
+	}
+	if tr.ActiveItem != c || !a.Open || tr.rowOf(c) != tr.Viewtop+tr.ActiveLine {
+		t.Fatal("the cursor should follow the moved item")
+	}
+	if err := a.MoveItem(c, 0); err != ErrMoveIntoSelf {
+		t.Fatalf("expected ErrMoveIntoSelf, got %v", err)
+	}
+}
+
+func TestReplaceChildren(t *testing.T) {
+	tr := newTestTree(10)
+	a := tr.Items[0]
+	a.OpenChildren()
+	tr.SetActive(a.Children[1])
+
+	a.ReplaceChildren(
+		NewItem("a2", false, nil, nil, nil, nil, nil, nil, nil),
+		NewItem("a3", false, nil, nil, nil, nil, nil, nil, nil),
+	)
+	if names(a.Children) != "a2,a3" || tr.ActiveItem != a.Children[0] {
+		t.Fatal("the cursor should stay on the item with the same path")
+	}
+	a.ReplaceChildren(NewItem("x", false, nil, nil, nil, nil, nil, nil, nil))
+	if tr.ActiveItem != a {
+		t.Fatalf("expected the cursor on a, got %s", tr.ActiveItem.Name)
+	}
+
+	tr.SetActive(tr.Items[2])
+	tr.ReplaceChildren(NewItem("c", false, nil, nil, nil, nil, nil, nil, nil))
+	if tr.ActiveItem != tr.Items[0] || tr.ActiveItem.GetParent() != tr {
+		t.Fatal("the cursor should move to the new c")
+	}
+	tr.Refresh()
+	if tr.ActiveItem != nil || len(tr.Items) != 0 {
+		t.Fatal("refresh should empty the tree")
+	}
+}
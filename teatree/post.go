@@ -0,0 +1,115 @@
+package teatree
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// A Tree belongs to the goroutine running its Update, and its methods must only be called
+// from there, or before the program starts. Other goroutines hand their changes to Post, or
+// feed new items to a channel passed to Stream, and they are applied by Update.
+
+// streamBatch is the most items a Stream adds to the tree per message.
+const streamBatch = 100
+
+// postedMsg tells the Update goroutine that funcs have been handed to Post.
+type postedMsg struct {
+	tree *Tree
+}
+
+// streamedMsg carries items read from a channel passed to Stream.
+type streamedMsg struct {
+	tree   *Tree
+	ch     <-chan *TreeItem
+	items  []*TreeItem
+	closed bool
+}
+
+// Post has fn called with the tree on the Update goroutine, where it is free to change the
+// tree. It is safe to call from any goroutine, and doesn't block. Funcs are applied by a
+// command returned from Init and Focus, so a host model must return the tree's Init from its
+// own, unless the tree is in a form, which focuses it. A form only passes messages to the
+// field with focus, so funcs posted while the tree is blurred wait for it to be focused again.
+func (t *Tree) Post(fn func(*Tree)) {
+	t.postMu.Lock()
+	t.posted = append(t.posted, fn)
+	t.postMu.Unlock()
+	select {
+	case t.wake <- struct{}{}:
+	default:
+		// A wake up is already pending
+	}
+}
+
+// waitForPost returns a command that waits for funcs to be posted, or nil if one is already
+// waiting.
+func (t *Tree) waitForPost() tea.Cmd {
+	if !t.waiting.CompareAndSwap(false, true) {
+		return nil
+	}
+	return func() tea.Msg {
+		<-t.wake
+		// The message may never reach the tree, so another command can be started from
+		// here on
+		t.waiting.Store(false)
+		return postedMsg{tree: t}
+	}
+}
+
+// applyPosted calls the funcs posted so far.
+func (t *Tree) applyPosted() {
+	t.postMu.Lock()
+	fns := t.posted
+	t.posted = nil
+	t.postMu.Unlock()
+	for _, fn := range fns {
+		fn(t)
+	}
+}
+
+// updatePosted applies posted funcs, and waits for more.
+func (t *Tree) updatePosted(msg postedMsg) tea.Cmd {
+	if msg.tree != t {
+		return nil
+	}
+	t.applyPosted()
+	return t.waitForPost()
+}
+
+// Stream returns a command that adds the items sent on ch to the top level of the tree, as
+// they arrive, until ch is closed. The command should be returned from the host model's
+// Init or Update.
+func (t *Tree) Stream(ch <-chan *TreeItem) tea.Cmd {
+	return func() tea.Msg {
+		item, ok := <-ch
+		if !ok {
+			return streamedMsg{tree: t, ch: ch, closed: true}
+		}
+		// Take whatever else is ready, so a fast producer doesn't cost a message per item
+		items := []*TreeItem{item}
+		for len(items) < streamBatch {
+			select {
+			case item, ok := <-ch:
+				if !ok {
+					return streamedMsg{tree: t, ch: ch, items: items, closed: true}
+				}
+				items = append(items, item)
+			default:
+				return streamedMsg{tree: t, ch: ch, items: items}
+			}
+		}
+		return streamedMsg{tree: t, ch: ch, items: items}
+	}
+}
+
+// updateStreamed adds streamed items to the tree, and waits for more unless the channel has
+// been closed.
+func (t *Tree) updateStreamed(msg streamedMsg) tea.Cmd {
+	if msg.tree != t {
+		return nil
+	}
+	if len(msg.items) > 0 {
+		t.AddChildren(msg.items...)
+	}
+	if msg.closed {
+		return nil
+	}
+	return t.Stream(msg.ch)
+}
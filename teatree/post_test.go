@@ -0,0 +1,143 @@
+package teatree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// TestConcurrentProducers feeds a tree from several goroutines at once, while this goroutine
+// plays the part of the bubbletea runtime. Run it with -race.
+func TestConcurrentProducers(t *testing.T) {
+	const producers, perProducer, streamed = 4, 50, 100
+	tr := newTestTree(10)
+	a := tr.Items[0]
+
+	msgs := make(chan tea.Msg)
+	done := make(chan struct{})
+	defer close(done)
+	// Commands run on goroutines of their own, and hand their messages to the Update loop
+	runCmd := func(cmd tea.Cmd) {
+		go func() {
+			select {
+			case msgs <- cmd():
+			case <-done:
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for x := 0; x < perProducer; x++ {
+				item := NewItem(fmt.Sprintf("p%d-%d", p, x), false, nil, nil, nil, nil, nil, nil, nil)
+				tr.Post(func(tr *Tree) {
+					a.AddChildren(item)
+				})
+			}
+		}(p)
+	}
+	ch := make(chan *TreeItem)
+	go func() {
+		for x := 0; x < streamed; x++ {
+			ch <- NewItem(fmt.Sprintf("s%d", x), false, nil, nil, nil, nil, nil, nil, nil)
+		}
+		close(ch)
+	}()
+
+	runCmd(tr.Init())
+	runCmd(tr.Stream(ch))
+	want := 2 + producers*perProducer
+	for len(a.Children) < want || len(tr.Items) < 3+streamed {
+		switch msg := (<-msgs).(type) {
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				if cmd != nil {
+					runCmd(cmd)
+				}
+			}
+		case postedMsg, streamedMsg:
+			if _, cmd := tr.Update(msg); cmd != nil {
+				runCmd(cmd)
+			}
+		}
+		// Draw and move around between messages, as a program would
+		tr.View()
+		tr.Update(keyMsg("j"))
+	}
+	wg.Wait()
+
+	if got := len(a.Children); got != want {
+		t.Fatalf("posted items: got %d, want %d", got, want)
+	}
+	if got := len(tr.Items); got != 3+streamed {
+		t.Fatalf("streamed items: got %d, want %d", got, 3+streamed)
+	}
+}
+
+func TestPostOtherTree(t *testing.T) {
+	tr, other := newTestTree(10), newTestTree(10)
+	called := false
+	wait := other.Init()
+	other.Post(func(*Tree) { called = true })
+	msg := wait()
+	if cmd := tr.updatePosted(msg.(postedMsg)); cmd != nil || called {
+		t.Fatal("a tree should ignore funcs posted to another")
+	}
+	if cmd := other.updatePosted(msg.(postedMsg)); cmd == nil || !called {
+		t.Fatal("posted func not applied")
+	}
+}
+
+// Forms never call a field's Init, so posted funcs have to be applied from Focus instead.
+func TestPostInForm(t *testing.T) {
+	tr := newTestTree(10)
+	form := huh.NewForm(huh.NewGroup(tr))
+	cmd := form.Init()
+
+	called := false
+	tr.Post(func(*Tree) { called = true })
+	msgs := run(cmd)
+	for _, msg := range msgs {
+		form.Update(msg)
+	}
+	if !called {
+		t.Fatalf("posted func not applied through the form, got %#v", msgs)
+	}
+	if tr.Init() != nil || tr.Focus() != nil {
+		t.Fatal("only one command should wait for posted funcs at a time")
+	}
+}
+
+// A form drops messages for fields without focus, so funcs posted while the tree is blurred
+// are applied when it gets focus back.
+func TestPostWhileBlurred(t *testing.T) {
+	tr := newTestTree(10)
+	form := huh.NewForm(huh.NewGroup(tr, huh.NewInput()))
+	wait := form.Init()
+	form.Update(form.NextField()())
+	if tr.Focused() {
+		t.Fatal("the input should have focus")
+	}
+
+	called := 0
+	tr.Post(func(*Tree) { called++ })
+	for _, msg := range run(wait) {
+		form.Update(msg)
+	}
+	if called != 0 {
+		t.Fatal("the form should not have passed the message to a blurred tree")
+	}
+	form.Update(form.PrevField()())
+	if called != 1 {
+		t.Fatalf("posted func applied %d times when the tree got focus back, want 1", called)
+	}
+	if !tr.waiting.Load() {
+		t.Fatal("the tree should be waiting for more funcs")
+	}
+}
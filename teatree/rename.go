@@ -0,0 +1,121 @@
+package teatree
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RenameFunc is called when the user commits a new name for ti. Returning an error rejects
+// the name: the error is shown next to the input, which stays open so it can be corrected.
+// Otherwise the item takes the new name. The func can update ti.Data to match.
+type RenameFunc func(ti *TreeItem, name string) error
+
+// rename holds the state of the inline edit of an item's name.
+type rename struct {
+	input textinput.Model
+	item  *TreeItem // The item being renamed, or nil
+	err   error     // Why the last name was rejected
+}
+
+// Renaming reports whether an item's name is being edited. Host models should pass keys
+// straight to the tree while it is, rather than acting on them as shortcuts.
+func (t *Tree) Renaming() bool {
+	return t.rename.item != nil
+}
+
+// Typing reports whether the tree has an input with focus, for search or renaming.
+func (t *Tree) Typing() bool {
+	return t.Searching() || t.Renaming()
+}
+
+// StartRename opens an input in place of the active item's name. It does nothing unless the
+// tree's Rename func is set.
+func (t *Tree) StartRename() tea.Cmd {
+	ti := t.ActiveItem
+	if t.Rename == nil || ti == nil || ti.placeholder || ti.Disabled {
+		return nil
+	}
+	if t.rename.input.Prompt == "" {
+		t.rename.input = textinput.New()
+		t.rename.input.Prompt = ""
+	}
+	t.rename.item = ti
+	t.rename.err = nil
+	t.rename.input.SetValue(ti.Name)
+	t.rename.input.CursorEnd()
+	t.updateKeys()
+	return t.rename.input.Focus()
+}
+
+// CancelRename closes the rename input, leaving the item's name as it was.
+func (t *Tree) CancelRename() {
+	t.rename.item = nil
+	t.rename.err = nil
+	t.rename.input.Blur()
+	t.updateKeys()
+}
+
+// CommitRename offers the text in the rename input to the tree's Rename func, and renames the
+// item if it is accepted. The item is moved to its place in the sort order under its new name.
+func (t *Tree) CommitRename() error {
+	ti := t.rename.item
+	if ti == nil {
+		return nil
+	}
+	name := t.rename.input.Value()
+	if name != ti.Name {
+		if err := t.Rename(ti, name); err != nil {
+			t.rename.err = err
+			return err
+		}
+		old := ti.Name
+		t.setName(ti, name)
+		t.record(fmt.Sprintf("rename %s to %s", old, name), []*TreeItem{ti}, func() {
+			t.setName(ti, old)
+		}, func() {
+			t.setName(ti, name)
+		})
+		t.emit(RenamedMsg{Tree: t, Item: ti, Path: ti.GetPath(), OldName: old})
+	}
+	t.CancelRename()
+	return nil
+}
+
+// setName renames ti and moves it to its place in the sort order.
+func (t *Tree) setName(ti *TreeItem, name string) {
+	t.keepCursor(func() {
+		ti.Name = name
+		if par := ti.GetParent(); par != nil {
+			sortChildren(par)
+		}
+	})
+}
+
+// updateRename handles a key while the rename input has focus.
+func (t *Tree) updateRename(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, t.KeyMap.CancelRename):
+		t.CancelRename()
+		return nil
+	case key.Matches(msg, t.KeyMap.AcceptRename):
+		t.CommitRename()
+		return nil
+	}
+	var cmd tea.Cmd
+	t.rename.input, cmd = t.rename.input.Update(msg)
+	// A rejection is only shown until the name is changed
+	t.rename.err = nil
+	return cmd
+}
+
+// renameView renders the rename input, with the reason the last name was rejected.
+func (t *Tree) renameView() string {
+	s := t.rename.input.View()
+	if t.rename.err != nil {
+		s += " " + t.Styles.Error.Render(t.rename.err.Error())
+	}
+	return s
+}
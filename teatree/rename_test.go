@@ -0,0 +1,76 @@
+package teatree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Update(keyMsg("e"))
+	if tr.Renaming() {
+		t.Fatal("renaming should be off until a Rename func is set")
+	}
+
+	tr.Rename = func(ti *TreeItem, name string) error {
+		if strings.Contains(name, "!") {
+			return errors.New("no shouting")
+		}
+		return nil
+	}
+	tr.Update(keyMsg("e"))
+	if !tr.Renaming() || !tr.Typing() {
+		t.Fatal("e should open the rename input")
+	}
+	// Keys go to the input, not the tree
+	tr.Update(keyMsg("j"))
+	tr.Update(keyMsg("!"))
+	if tr.ActiveItem.Name != "a" {
+		t.Fatalf("cursor moved while renaming, to %q", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("enter"))
+	if !tr.Renaming() || !strings.Contains(tr.View(), "no shouting") {
+		t.Fatalf("rejected name should leave the input open with the error:\n%s", tr.View())
+	}
+	if tr.ActiveItem.Name != "a" {
+		t.Fatalf("rejected name was applied: %q", tr.ActiveItem.Name)
+	}
+
+	tr.rename.input.SetValue("z")
+	_, cmd := tr.Update(keyMsg("enter"))
+	if tr.Renaming() || tr.ActiveItem.Name != "z" {
+		t.Fatalf("rename not applied: renaming %v, name %q", tr.Renaming(), tr.ActiveItem.Name)
+	}
+	var renamed *RenamedMsg
+	for _, msg := range run(cmd) {
+		if m, ok := msg.(RenamedMsg); ok {
+			renamed = &m
+		}
+	}
+	if renamed == nil || renamed.OldName != "a" || strings.Join(renamed.Path, "/") != "z" {
+		t.Fatalf("expected RenamedMsg from a to z, got %#v", renamed)
+	}
+
+	tr.Update(keyMsg("e"))
+	tr.Update(keyMsg("x"))
+	tr.Update(keyMsg("esc"))
+	if tr.Renaming() || tr.ActiveItem.Name != "z" {
+		t.Fatalf("esc should cancel: renaming %v, name %q", tr.Renaming(), tr.ActiveItem.Name)
+	}
+}
+
+func TestRenameResorts(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SetSortMode("natural")
+	tr.Rename = func(*TreeItem, string) error { return nil }
+	tr.Update(keyMsg("e"))
+	tr.rename.input.SetValue("d")
+	tr.Update(keyMsg("enter"))
+	if got := names(tr.visibleItems()); got != "b,c,d" {
+		t.Fatalf("renamed item not moved into order: got %s", got)
+	}
+	if tr.ActiveItem.Name != "d" {
+		t.Fatalf("cursor should stay on the renamed item, got %q", tr.ActiveItem.Name)
+	}
+}
@@ -0,0 +1,202 @@
+package teatree
+
+import "strings"
+
+// row is one line of the tree's view: an item, how deeply it is nested, and whether it is
+// the last of its siblings on display.
+type row struct {
+	item  *TreeItem
+	depth int
+	last  bool
+}
+
+// Invalidate throws away the cached list of visible rows, so that it is rebuilt the next
+// time it is needed. The tree does this itself whenever items are added, removed, opened or
+// closed through its methods; call it after changing Children or Open directly.
+func (t *Tree) Invalidate() {
+	t.rowsValid = false
+}
+
+// invalidate marks the row cache of the item's tree as stale.
+func (ti *TreeItem) invalidate() {
+	if ti.parentTree != nil {
+		ti.parentTree.Invalidate()
+	}
+}
+
+// visibleRows returns every row that is on display, in the order they are drawn. The list
+// is cached until the tree is next invalidated.
+func (t *Tree) visibleRows() []row {
+	if t.rowsValid {
+		return t.rows
+	}
+	if t.search.filtering() {
+		t.refilter()
+	}
+	t.rows = t.rows[:0]
+	t.rowIndex = make(map[*TreeItem]int, len(t.rowIndex))
+	var walk func([]*TreeItem, int)
+	walk = func(list []*TreeItem, depth int) {
+		lastShown := len(list) - 1
+		for lastShown >= 0 && !t.shown(list[lastShown]) {
+			lastShown--
+		}
+		for x, item := range list {
+			if !t.shown(item) {
+				continue
+			}
+			t.rowIndex[item] = len(t.rows)
+			t.rows = append(t.rows, row{item: item, depth: depth, last: x == lastShown})
+			if item.Open && len(item.Children) > 0 {
+				walk(item.Children, depth+1)
+			}
+		}
+	}
+	walk(t.Items, 0)
+	t.rowsValid = true
+	return t.rows
+}
+
+// rowOf returns the index of the row showing ti, or -1 if it isn't on display.
+func (t *Tree) rowOf(ti *TreeItem) int {
+	t.visibleRows()
+	if x, ok := t.rowIndex[ti]; ok {
+		return x
+	}
+	return -1
+}
+
+// ActiveShown reports whether the active item is on display. It may not be while a search
+// filter is hiding it, and then it can't be selected.
+func (t *Tree) ActiveShown() bool {
+	return t.ActiveItem != nil && t.rowOf(t.ActiveItem) >= 0
+}
+
+// visibleItems returns the items on display, in the order they are drawn.
+func (t *Tree) visibleItems() []*TreeItem {
+	rows := t.visibleRows()
+	items := make([]*TreeItem, len(rows))
+	for x, r := range rows {
+		items[x] = r.item
+	}
+	return items
+}
+
+// scrollToActive derives ActiveLine and Viewtop from the row of the active item. Viewtop is
+// moved as little as possible to bring the cursor on screen, and never past the last page.
+func (t *Tree) scrollToActive() {
+	rows := t.visibleRows()
+	height := t.viewHeight()
+	if height > 0 {
+		t.Viewtop = min(t.Viewtop, max(len(rows)-height, 0))
+	}
+	t.Viewtop = max(t.Viewtop, 0)
+
+	idx := t.rowOf(t.ActiveItem)
+	if idx < 0 {
+		return
+	}
+	if idx < t.Viewtop {
+		t.Viewtop = idx
+	}
+	if height > 0 && idx >= t.Viewtop+height {
+		t.Viewtop = idx - height + 1
+	}
+	t.ActiveLine = idx - t.Viewtop
+}
+
+// moveActive moves the cursor n visible rows down, or up if n is negative, stopping at
+// either end of the tree.
+func (t *Tree) moveActive(n int) {
+	rows := t.visibleRows()
+	if len(rows) == 0 {
+		return
+	}
+	// If the cursor is on something that's been hidden, start again from the top
+	idx := max(t.rowOf(t.ActiveItem), 0)
+	idx = min(max(idx+n, 0), len(rows)-1)
+	t.SetActive(rows[idx].item)
+	t.scrollToActive()
+}
+
+// renderRow draws a single line of the tree, with its columns.
+func (t *Tree) renderRow(r row) string {
+	return t.withColumns(t.rowLabel(r), r.item)
+}
+
+// rowLabel draws the tree part of a row: the indentation, chevron, checkbox, icon and name,
+// scrolled Viewleft cells to the left.
+func (t *Tree) rowLabel(r row) string {
+	ti := r.item
+	indent, chevron, box := t.indent(r), t.chevron(ti), t.checkbox(ti)
+	if box != "" {
+		box += " "
+	}
+	icon, sep, name := ti.Icon(), " ", ti.Name
+	skip := t.Viewleft
+	for _, part := range []*string{&indent, &chevron, &box, &icon, &sep, &name} {
+		*part, skip = skipCells(*part, skip)
+	}
+
+	baseline := t.rowStyle(ti)
+	istyle := baseline.Inherit(ti.IconStyle())
+	lstyle := baseline.Inherit(ti.LabelStyle())
+	if ti.Disabled {
+		// Greyed out, whatever the item's own colours
+		istyle, lstyle = baseline, baseline
+	}
+	label := t.highlightMatches(name, lstyle)
+	if t.rename.item == ti {
+		label = t.renameView()
+	}
+	return t.Styles.Guide.Render(indent) + t.Styles.Chevron.Render(chevron) + box +
+		istyle.Render(icon) + baseline.Render(sep) + label
+}
+
+// View draws the title and description, then the rows that fit in the tree's Height,
+// starting at Viewtop. Only those rows are visited, so the cost doesn't grow with the size of
+// the tree.
+func (t *Tree) View() string {
+	if !t.initialized {
+		return ""
+	}
+	t.scrollToActive()
+
+	var lines []string
+	if header := t.fieldHeader(); header != "" {
+		lines = append(lines, header)
+	}
+	if t.ShowBreadcrumb {
+		lines = append(lines, t.breadcrumb())
+	}
+	if t.search.active() {
+		lines = append(lines, t.searchView())
+	}
+
+	rows := t.visibleRows()
+	end := len(rows)
+	if height := t.viewHeight(); height > 0 {
+		end = min(t.Viewtop+height, end)
+	}
+	if len(t.Columns) > 0 {
+		t.layout = t.layoutColumns(rows[t.Viewtop:end])
+		lines = append(lines, t.columnHeader())
+	}
+	for x := t.Viewtop; x < end; x++ {
+		lines = append(lines, t.renderRow(rows[x]))
+	}
+	if t.ShowActiveLabel {
+		lines = append(lines, t.activeLabel())
+	}
+	if t.ShowStatus {
+		lines = append(lines, t.statusLine())
+	}
+	if t.ShowHelp {
+		lines = append(lines, t.helpView())
+	}
+	view := strings.Join(lines, "\n")
+	if t.theme == nil {
+		return view
+	}
+	return t.fieldStyles().Base.Render(view)
+}
@@ -0,0 +1,72 @@
+package teatree
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newWideTree builds a tree of n open folders holding ten files each.
+func newWideTree(n int) *Tree {
+	tr := New().(*Tree)
+	for x := 0; x < n; x++ {
+		dir := NewItem(fmt.Sprintf("dir%d", x), true, nil, nil, nil, nil, nil, nil, nil)
+		for y := 0; y < 10; y++ {
+			dir.AddChildren(NewItem(fmt.Sprintf("file%d", y), false, nil, nil, nil, nil, nil, nil, nil))
+		}
+		dir.Open = true
+		tr.AddChildren(dir)
+	}
+	return tr
+}
+
+func TestViewRendersOnlyTheViewport(t *testing.T) {
+	tr := newWideTree(3)
+	tr.Update(tea.WindowSizeMsg{Width: 40, Height: 5})
+	for x := 0; x < 12; x++ {
+		tr.Update(keyMsg("j"))
+	}
+	lines := strings.Split(tr.View(), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5", len(lines))
+	}
+	if !strings.Contains(lines[tr.ActiveLine], "file0") || tr.ActiveItem.Name != "file0" {
+		t.Fatalf("cursor line %d shows %q, active %q", tr.ActiveLine, lines[tr.ActiveLine], tr.ActiveItem.Name)
+	}
+}
+
+func TestCursorSurvivesResize(t *testing.T) {
+	tr := newWideTree(3)
+	tr.Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	tr.SelectLast()
+	tr.Update(tea.WindowSizeMsg{Width: 40, Height: 4})
+	if tr.ActiveLine != 3 || tr.Viewtop != tr.CountVisibleItems()-4 {
+		t.Fatalf("after shrinking: line %d, top %d", tr.ActiveLine, tr.Viewtop)
+	}
+	tr.Update(tea.WindowSizeMsg{Width: 40, Height: 100})
+	if tr.Viewtop != 0 || tr.ActiveLine != tr.CountVisibleItems()-1 {
+		t.Fatalf("after growing: line %d, top %d", tr.ActiveLine, tr.Viewtop)
+	}
+
+	// Going back up from the bottom never leaves the cursor off screen
+	tr.Update(tea.WindowSizeMsg{Width: 40, Height: 4})
+	for x := 0; x < 10; x++ {
+		tr.Update(keyMsg("k"))
+		if tr.ActiveLine < 0 || tr.ActiveLine >= 4 {
+			t.Fatalf("cursor drifted to line %d", tr.ActiveLine)
+		}
+	}
+}
+
+func BenchmarkNavigateLargeTree(b *testing.B) {
+	tr := newWideTree(2000)
+	tr.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	down := keyMsg("j")
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		tr.Update(down)
+		tr.View()
+	}
+}
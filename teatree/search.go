@@ -0,0 +1,250 @@
+package teatree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// search holds the state of the incremental filter. While a query is set, only the items
+// that match it, plus their ancestors, are shown.
+type search struct {
+	input   textinput.Model
+	typing  bool // The input line has focus and is receiving keys
+	query   string
+	matches []*TreeItem        // Matching items, in display order
+	visible map[*TreeItem]bool // Matches and their ancestors
+	current int                // Index into matches of the match the cursor was last sent to
+
+	// What to put back when the search is cancelled
+	savedOpen   map[*TreeItem]bool
+	savedActive *TreeItem
+	savedTop    int
+}
+
+// filtering reports whether a query is currently hiding items.
+func (s *search) filtering() bool {
+	return s.query != ""
+}
+
+// active reports whether the search line should be drawn.
+func (s *search) active() bool {
+	return s.typing || s.filtering()
+}
+
+// Searching reports whether the search input has focus. Host models should pass keys
+// straight to the tree while it does, rather than acting on them as shortcuts.
+func (t *Tree) Searching() bool {
+	return t.search.typing
+}
+
+// shown reports whether ti survives the current search filter.
+func (t *Tree) shown(ti *TreeItem) bool {
+	return !t.search.filtering() || t.search.visible[ti]
+}
+
+// StartSearch opens the search input. The expansion state and cursor are remembered so
+// that CancelSearch can put them back.
+func (t *Tree) StartSearch() tea.Cmd {
+	if !t.search.active() {
+		t.search.savedOpen = map[*TreeItem]bool{}
+		forEachItem(t.Items, func(ti *TreeItem) {
+			t.search.savedOpen[ti] = ti.Open
+		})
+		t.search.savedActive = t.ActiveItem
+		t.search.savedTop = t.Viewtop
+	}
+	if t.search.input.Prompt == "" {
+		t.search.input = textinput.New()
+		t.search.input.Prompt = "/"
+	}
+	t.search.input.SetValue(t.search.query)
+	t.search.input.CursorEnd()
+	t.search.typing = true
+	t.updateKeys()
+	return t.search.input.Focus()
+}
+
+// CancelSearch leaves search mode, restoring the expansion state and cursor from before the
+// search started.
+func (t *Tree) CancelSearch() {
+	if !t.search.active() {
+		return
+	}
+	for ti, open := range t.search.savedOpen {
+		ti.Open = open
+	}
+	if t.search.savedActive != nil {
+		t.SetActive(t.search.savedActive)
+	}
+	top := t.search.savedTop
+	t.search = search{input: t.search.input}
+	t.search.input.Blur()
+	t.search.input.SetValue("")
+	t.Invalidate()
+	t.Viewtop = top
+	t.scrollToActive()
+	t.updateKeys()
+}
+
+// SetSearch filters the tree down to the items whose names contain query, ignoring case,
+// and moves the cursor to the first of them.
+func (t *Tree) SetSearch(query string) {
+	if !t.search.active() {
+		t.StartSearch()
+		t.search.typing = false
+		t.search.input.Blur()
+	}
+	// Start over from the expansion state the user had before searching
+	for ti, open := range t.search.savedOpen {
+		ti.Open = open
+	}
+	t.search.query = query
+	t.search.matches = nil
+	t.search.visible = nil
+	t.search.current = 0
+	t.updateKeys()
+	t.Invalidate()
+
+	if query == "" {
+		if t.search.savedActive != nil {
+			t.SetActive(t.search.savedActive)
+		}
+		t.scrollToActive()
+		return
+	}
+
+	t.refilter()
+	for _, ti := range t.search.matches {
+		for par, ok := ti.GetParent().(*TreeItem); ok; par, ok = par.GetParent().(*TreeItem) {
+			par.Open = true
+		}
+	}
+	if len(t.search.matches) > 0 {
+		t.SetActive(t.search.matches[0])
+	}
+	t.Invalidate()
+	t.Viewtop = 0
+	t.scrollToActive()
+}
+
+// refilter works out which items match the query, and which are shown because something
+// below them matches. It is run each time the rows are rebuilt, so that items loaded or
+// added while the filter is in place are picked up.
+func (t *Tree) refilter() {
+	t.search.matches = nil
+	t.search.visible = map[*TreeItem]bool{}
+	lq := strings.ToLower(t.search.query)
+	forEachItem(t.Items, func(ti *TreeItem) {
+		if !strings.Contains(strings.ToLower(ti.Name), lq) {
+			return
+		}
+		t.search.matches = append(t.search.matches, ti)
+		t.search.visible[ti] = true
+		for par, ok := ti.GetParent().(*TreeItem); ok; par, ok = par.GetParent().(*TreeItem) {
+			t.search.visible[par] = true
+		}
+	})
+	t.search.current = min(t.search.current, max(len(t.search.matches)-1, 0))
+}
+
+// NextMatch moves the cursor to the next search match, wrapping around at the end.
+func (t *Tree) NextMatch() {
+	t.cycleMatch(1)
+}
+
+// PrevMatch moves the cursor to the previous search match, wrapping around at the start.
+func (t *Tree) PrevMatch() {
+	t.cycleMatch(-1)
+}
+
+func (t *Tree) cycleMatch(n int) {
+	count := len(t.search.matches)
+	if count == 0 {
+		return
+	}
+	t.search.current = ((t.search.current+n)%count + count) % count
+	t.SetActive(t.search.matches[t.search.current])
+	t.scrollToActive()
+}
+
+// updateSearch handles a key while the search input has focus. Enter closes the input but
+// leaves the filter in place so that the matches can be cycled through.
+func (t *Tree) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, t.KeyMap.CancelSearch):
+		t.CancelSearch()
+		return nil
+	case key.Matches(msg, t.KeyMap.AcceptSearch):
+		t.search.typing = false
+		t.search.input.Blur()
+		if !t.search.filtering() {
+			t.CancelSearch()
+		}
+		t.updateKeys()
+		return nil
+	}
+	var cmd tea.Cmd
+	t.search.input, cmd = t.search.input.Update(msg)
+	if q := t.search.input.Value(); q != t.search.query {
+		t.SetSearch(q)
+	}
+	return cmd
+}
+
+// searchView renders the search line shown above the tree.
+func (t *Tree) searchView() string {
+	var s string
+	if t.search.typing {
+		s = t.search.input.View()
+	} else {
+		s = "/" + t.search.query
+	}
+	if t.search.filtering() {
+		if len(t.search.matches) == 0 {
+			s += t.Styles.SearchInfo.Render("  no matches")
+		} else {
+			s += t.Styles.SearchInfo.Render(fmt.Sprintf("  %d/%d", t.search.current+1, len(t.search.matches)))
+		}
+	}
+	return s
+}
+
+// highlightMatches renders label in style, picking out every occurrence of the search query.
+func (t *Tree) highlightMatches(label string, style lipgloss.Style) string {
+	q := strings.ToLower(t.search.query)
+	lower := strings.ToLower(label)
+	// Case folding can change byte lengths, which would throw the offsets out
+	if q == "" || len(lower) != len(label) {
+		return style.Render(label)
+	}
+	match := t.Styles.SearchMatch.Inherit(style)
+	var sb strings.Builder
+	for {
+		x := strings.Index(lower, q)
+		if x < 0 {
+			break
+		}
+		if x > 0 {
+			sb.WriteString(style.Render(label[:x]))
+		}
+		sb.WriteString(match.Render(label[x : x+len(q)]))
+		label, lower = label[x+len(q):], lower[x+len(q):]
+	}
+	if label != "" {
+		sb.WriteString(style.Render(label))
+	}
+	return sb.String()
+}
+
+// forEachItem calls fn on every loaded item, depth first, whether it is open or not.
+func forEachItem(items []*TreeItem, fn func(*TreeItem)) {
+	for _, item := range items {
+		fn(item)
+		forEachItem(item.Children, fn)
+	}
+}
@@ -0,0 +1,89 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+)
+
+func names(items []*TreeItem) string {
+	var s []string
+	for _, item := range items {
+		s = append(s, item.Name)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestSearch(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Update(keyMsg("j"))
+
+	tr.Update(keyMsg("/"))
+	tr.Update(keyMsg("1"))
+	if got := names(tr.visibleItems()); got != "a,a1,b,b1" {
+		t.Fatalf("filtered rows: got %s", got)
+	}
+	if tr.ActiveItem.Name != "a1" {
+		t.Fatalf("cursor should jump to the first match, got %q", tr.ActiveItem.Name)
+	}
+	if !strings.Contains(tr.View(), "/1") {
+		t.Fatal("search line is not drawn")
+	}
+
+	// n would have been typed into the query while the input has focus
+	tr.Update(keyMsg("enter"))
+	tr.Update(keyMsg("n"))
+	if tr.ActiveItem.Name != "b1" {
+		t.Fatalf("next match: got %q, want b1", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("n"))
+	if tr.ActiveItem.Name != "a1" {
+		t.Fatalf("next match should wrap: got %q, want a1", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("N"))
+	if tr.ActiveItem.Name != "b1" {
+		t.Fatalf("prev match should wrap: got %q, want b1", tr.ActiveItem.Name)
+	}
+
+	tr.Update(keyMsg("esc"))
+	if got := names(tr.visibleItems()); got != "a,b,c" {
+		t.Fatalf("expansion not restored: got %s", got)
+	}
+	if tr.ActiveItem.Name != "b" {
+		t.Fatalf("cursor not restored: got %q, want b", tr.ActiveItem.Name)
+	}
+}
+
+func TestSearchNoMatches(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SetSearch("zzz")
+	if got := names(tr.visibleItems()); got != "" {
+		t.Fatalf("expected nothing visible, got %s", got)
+	}
+	if !strings.Contains(tr.View(), "no matches") {
+		t.Fatal("no matches message missing")
+	}
+	tr.CancelSearch()
+	if got := names(tr.visibleItems()); got != "a,b,c" {
+		t.Fatalf("got %s after cancel", got)
+	}
+}
+
+func TestSearchHiddenCursor(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SetSearch("zzz")
+	if tr.ActiveShown() {
+		t.Fatal("the cursor should be hidden when nothing matches")
+	}
+	if _, cmd := tr.Update(keyMsg("enter")); len(run(cmd)) != 0 {
+		t.Fatal("a hidden item should not be selectable")
+	}
+
+	// Items added while the filter is in place are found
+	tr.AddChildren(NewItem("zzz", false, nil, nil, nil, nil, nil, nil, nil))
+	if got := names(tr.visibleItems()); got != "zzz" {
+		t.Fatalf("new match not shown: got %s", got)
+	}
+	if len(tr.search.matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(tr.search.matches))
+	}
+}
@@ -0,0 +1,128 @@
+package teatree
+
+// SelectionMode controls whether the tree chooses a single item or lets the user check off
+// several of them.
+type SelectionMode int
+
+const (
+	// SelectSingle: the value of the tree is the active item. This is the default.
+	SelectSingle SelectionMode = iota
+	// SelectMulti: each item has a checkbox that is toggled on its own.
+	SelectMulti
+	// SelectCascade: checking an item checks its whole subtree, and a parent whose subtree is
+	// only partly checked shows as indeterminate.
+	SelectCascade
+)
+
+const (
+	CheckboxChecked   = "[x]"
+	CheckboxUnchecked = "[ ]"
+	CheckboxPartial   = "[-]"
+)
+
+// ToggleChecked flips the checkbox on ti. A partially checked item becomes fully checked.
+func (t *Tree) ToggleChecked(ti *TreeItem) {
+	if ti.Disabled {
+		return
+	}
+	t.SetChecked(ti, !ti.Checked)
+}
+
+// SetChecked sets the checkbox on ti. In SelectCascade mode the change is applied to the
+// whole subtree, and the ancestors of ti are updated to match. It does nothing in
+// SelectSingle mode, or to a loading or error row.
+func (t *Tree) SetChecked(ti *TreeItem, checked bool) {
+	if ti.placeholder {
+		return
+	}
+	switch t.SelectionMode {
+	case SelectMulti:
+		if ti.Checked != checked {
+			ti.Checked = checked
+			addChecked(ti.GetParent(), flag(checked))
+		}
+	case SelectCascade:
+		before := ti.checkedCount()
+		forEachItem([]*TreeItem{ti}, func(child *TreeItem) {
+			// Loading and error rows stand in for children, and aren't part of the value
+			child.Checked = checked && !child.placeholder
+		})
+		countChecked(ti)
+		delta := ti.checkedCount() - before
+		for par, ok := ti.GetParent().(*TreeItem); ok; par, ok = par.GetParent().(*TreeItem) {
+			par.checkedBelow += delta
+			if all := allChecked(par.Children); all != par.Checked {
+				par.Checked = all
+				delta += flag(all)
+			}
+		}
+	}
+}
+
+// Checked returns every checked item, in tree order.
+func (t *Tree) Checked() []*TreeItem {
+	var checked []*TreeItem
+	forEachItem(t.Items, func(ti *TreeItem) {
+		if ti.Checked && !ti.placeholder {
+			checked = append(checked, ti)
+		}
+	})
+	return checked
+}
+
+// checkbox returns the glyph showing the checked state of ti, or "" in SelectSingle mode.
+func (t *Tree) checkbox(ti *TreeItem) string {
+	switch {
+	case t.SelectionMode == SelectSingle || ti.placeholder:
+		return ""
+	case ti.Checked:
+		return t.Glyphs.Checked
+	case t.SelectionMode == SelectCascade && ti.checkedBelow > 0:
+		return t.Glyphs.Partial
+	}
+	return t.Glyphs.Unchecked
+}
+
+func allChecked(items []*TreeItem) bool {
+	for _, item := range items {
+		if !item.Checked && !item.placeholder {
+			return false
+		}
+	}
+	return true
+}
+
+// checkedCount is how many of ti and its descendants are checked.
+func (ti *TreeItem) checkedCount() int {
+	if ti.Checked {
+		return ti.checkedBelow + 1
+	}
+	return ti.checkedBelow
+}
+
+// countChecked works out checkedBelow for ti and everything below it, for items whose
+// Checked flags were set directly.
+func countChecked(ti *TreeItem) {
+	ti.checkedBelow = 0
+	for _, child := range ti.Children {
+		countChecked(child)
+		ti.checkedBelow += child.checkedCount()
+	}
+}
+
+// addChecked adds n to the count of checked descendants of h and each of its ancestors. It
+// is called whenever items are checked, unchecked, added or removed, so that drawing a row
+// doesn't have to search the item's subtree for its partial state.
+func addChecked(h ItemHolder, n int) {
+	for par, ok := h.(*TreeItem); ok && n != 0; par, ok = par.GetParent().(*TreeItem) {
+		par.checkedBelow += n
+	}
+}
+
+// flag returns 1 for a checked item and -1 for an unchecked one.
+func flag(checked bool) int {
+	if checked {
+		return 1
+	}
+	return -1
+}
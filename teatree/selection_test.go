@@ -0,0 +1,118 @@
+package teatree
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCascadeSelection(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SelectionMode = SelectCascade
+	a := tr.Items[0]
+	a.OpenChildren()
+
+	// Check a1 only: a is partly checked
+	tr.Update(keyMsg("j"))
+	tr.Update(keyMsg("c"))
+	if a.Checked || tr.checkbox(a) != CheckboxPartial {
+		t.Fatalf("parent should be indeterminate, got %s", tr.checkbox(a))
+	}
+	if !strings.Contains(tr.View(), CheckboxPartial) {
+		t.Fatal("indeterminate glyph not drawn")
+	}
+
+	// Check a2 as well: a becomes checked
+	tr.Update(keyMsg("j"))
+	tr.Update(keyMsg("c"))
+	if !a.Checked {
+		t.Fatal("parent should be checked once all children are")
+	}
+
+	// Checking b checks b1 too
+	tr.ToggleChecked(tr.Items[1])
+	want := [][]string{{"a"}, {"a", "a1"}, {"a", "a2"}, {"b"}, {"b", "b1"}}
+	if got := tr.GetValue(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// Unchecking a clears the subtree
+	tr.ToggleChecked(a)
+	if a.Children[0].Checked || a.Children[1].Checked {
+		t.Fatal("unchecking the parent should clear its children")
+	}
+}
+
+func TestMultiSelection(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Update(keyMsg("c"))
+	if tr.Items[0].Checked {
+		t.Fatal("check key should do nothing in single mode")
+	}
+	if got := tr.GetValue(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("single mode value: got %v", got)
+	}
+
+	tr.SelectionMode = SelectMulti
+	tr.Update(keyMsg("c"))
+	if !tr.Items[0].Checked || tr.Items[0].Children[0].Checked {
+		t.Fatal("multi mode should only check the active item")
+	}
+}
+
+// The partial state is kept up to date as items are checked, added, moved and removed.
+func TestCascadePartialCounts(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SelectionMode = SelectCascade
+	a, b := tr.Items[0], tr.Items[1]
+	deep := NewItem("a1x", false, nil, nil, nil, nil, nil, nil, nil)
+	a.Children[0].AddChildren(deep)
+
+	tr.SetChecked(deep, true)
+	if tr.checkbox(a) != CheckboxPartial {
+		t.Fatalf("a should be partial, got %s", tr.checkbox(a))
+	}
+	if err := tr.MoveItem(a.Children[0], b, 0); err != nil {
+		t.Fatal(err)
+	}
+	if tr.checkbox(a) != CheckboxUnchecked || tr.checkbox(b) != CheckboxPartial {
+		t.Fatalf("after the move: a %s, b %s", tr.checkbox(a), tr.checkbox(b))
+	}
+
+	// Items checked before they are added count too
+	pre := NewItem("pre", false, nil, nil, nil, nil, nil, nil, nil)
+	pre.Checked = true
+	a.AddChildren(pre)
+	if tr.checkbox(a) != CheckboxPartial {
+		t.Fatalf("a should be partial once a checked item is added, got %s", tr.checkbox(a))
+	}
+	a.RemoveItem(pre)
+	if tr.checkbox(a) != CheckboxUnchecked {
+		t.Fatalf("a should be unchecked once the item is removed, got %s", tr.checkbox(a))
+	}
+}
+
+func TestCascadeSkipsPlaceholders(t *testing.T) {
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return nil, errors.New("denied")
+	})
+	tr.SelectionMode = SelectCascade
+	dir.ToggleChildren()
+	for _, msg := range run(tr.takeCmds(nil)) {
+		tr.Update(msg)
+	}
+	if len(dir.Children) != 1 || !dir.Children[0].placeholder {
+		t.Fatal("expected an error row")
+	}
+
+	tr.SetChecked(dir, true)
+	if want := [][]string{{"dir"}}; !reflect.DeepEqual(tr.GetValue(), want) {
+		t.Fatalf("got %v, want %v", tr.GetValue(), want)
+	}
+	tr.SetChecked(dir.Children[0], true)
+	if dir.Children[0].Checked {
+		t.Fatal("an error row should not be checkable")
+	}
+}
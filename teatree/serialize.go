@@ -0,0 +1,168 @@
+package teatree
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TypeRegistry records the concrete types stored in TreeItem.Data, so that they can be
+// rebuilt when a tree is loaded. Each type is saved under the name it was registered with.
+type TypeRegistry struct {
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		byName: map[string]reflect.Type{},
+		byType: map[reflect.Type]string{},
+	}
+}
+
+// Register adds the type of sample under name. Pointer and value types are distinct, so
+// register &Thing{} if the items hold *Thing.
+func (r *TypeRegistry) Register(name string, sample any) *TypeRegistry {
+	typ := reflect.TypeOf(sample)
+	r.byName[name] = typ
+	r.byType[typ] = name
+	return r
+}
+
+// savedTree and savedItem are the JSON form of a tree.
+type savedTree struct {
+	Active []string    `json:"active,omitempty"`
+	Items  []savedItem `json:"items"`
+}
+
+type savedItem struct {
+	Name            string          `json:"name"`
+	ID              string          `json:"id,omitempty"`
+	CanHaveChildren bool            `json:"can_have_children,omitempty"`
+	Open            bool            `json:"open,omitempty"`
+	Checked         bool            `json:"checked,omitempty"`
+	Loaded          bool            `json:"loaded,omitempty"` // The children came from a LoadFunc, and don't need fetching again
+	Type            string          `json:"type,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	Children        []savedItem     `json:"children,omitempty"`
+}
+
+// MarshalTree saves the items of t as JSON: their names, Open and Checked flags, Data, and
+// the path of the active item. Data is saved under the name its type was registered with in
+// reg; an unregistered type is an error. Functions such as icons and LoadFuncs aren't saved,
+// and should be put back by the setup callback given to UnmarshalTree.
+func MarshalTree(t *Tree, reg *TypeRegistry) ([]byte, error) {
+	items, err := saveItems(t.Items, reg)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(savedTree{Active: t.activePath(), Items: items})
+}
+
+func saveItems(items []*TreeItem, reg *TypeRegistry) ([]savedItem, error) {
+	var saved []savedItem
+	for _, ti := range items {
+		if ti.placeholder {
+			continue
+		}
+		si := savedItem{
+			Name:            ti.Name,
+			ID:              ti.ID,
+			CanHaveChildren: ti.CanHaveChildren,
+			Open:            ti.Open,
+			Checked:         ti.Checked,
+			Loaded:          ti.loaded,
+		}
+		if ti.Data != nil {
+			name, ok := reg.byType[reflect.TypeOf(ti.Data)]
+			if !ok {
+				return nil, fmt.Errorf("teatree: the data type %T of %q is not registered", ti.Data, ti.Name)
+			}
+			data, err := json.Marshal(ti.Data)
+			if err != nil {
+				return nil, fmt.Errorf("teatree: saving the data of %q: %w", ti.Name, err)
+			}
+			si.Type, si.Data = name, data
+		}
+		children, err := saveItems(ti.Children, reg)
+		if err != nil {
+			return nil, err
+		}
+		si.Children = children
+		saved = append(saved, si)
+	}
+	return saved, nil
+}
+
+// UnmarshalTree replaces the items of t with a tree saved by MarshalTree, rebuilding Data
+// with the types in reg and linking every item to its parent and to t. setup, if not nil, is
+// called for each item once the whole tree is in place, so that icons, styles and LoadFuncs
+// can be attached. The cursor is put back on the item that was active.
+func UnmarshalTree(data []byte, t *Tree, reg *TypeRegistry, setup func(*TreeItem)) error {
+	var saved savedTree
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	items, err := loadItems(saved.Items, reg)
+	if err != nil {
+		return err
+	}
+
+	t.ReplaceChildren(items...)
+	if setup != nil {
+		forEachItem(t.Items, setup)
+	}
+	if ti := t.itemAt(saved.Active); ti != nil {
+		t.SetActive(ti)
+	}
+	t.Invalidate()
+	t.scrollToActive()
+	return nil
+}
+
+func loadItems(saved []savedItem, reg *TypeRegistry) ([]*TreeItem, error) {
+	var items []*TreeItem
+	for _, si := range saved {
+		ti := NewItem(si.Name, si.CanHaveChildren, nil, nil, nil, nil, nil, nil, nil)
+		ti.ID = si.ID
+		ti.Open = si.Open
+		ti.Checked = si.Checked
+		ti.loaded = si.Loaded
+		if si.Type != "" {
+			typ, ok := reg.byName[si.Type]
+			if !ok {
+				return nil, fmt.Errorf("teatree: the data type %q of %q is not registered", si.Type, si.Name)
+			}
+			data, err := decodeData(si.Data, typ)
+			if err != nil {
+				return nil, fmt.Errorf("teatree: loading the data of %q: %w", si.Name, err)
+			}
+			ti.Data = data
+		}
+		children, err := loadItems(si.Children, reg)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 {
+			ti.AddChildren(children...)
+		}
+		items = append(items, ti)
+	}
+	return items, nil
+}
+
+// decodeData unmarshals data into a new value of type typ.
+func decodeData(data json.RawMessage, typ reflect.Type) (any, error) {
+	if typ.Kind() == reflect.Pointer {
+		v := reflect.New(typ.Elem())
+		if err := json.Unmarshal(data, v.Interface()); err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,67 @@
+package teatree
+
+import (
+	"strings"
+	"testing"
+)
+
+type server struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestMarshalTree(t *testing.T) {
+	tr := newTestTree(10)
+	a, b := tr.Items[0], tr.Items[1]
+	a.Children[0].Data = &server{Host: "localhost", Port: 50000}
+	a.Children[1].Data = "note"
+	a.Children[0].SetID("srv")
+	a.OpenChildren()
+	tr.SetActive(a.Children[1])
+
+	reg := NewTypeRegistry().Register("server", &server{})
+	if _, err := MarshalTree(tr, reg); err == nil {
+		t.Fatal("expected an error for the unregistered string")
+	}
+	reg.Register("string", "")
+	data, err := MarshalTree(tr, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New().(*Tree)
+	var setup []string
+	err = UnmarshalTree(data, loaded, reg, func(ti *TreeItem) {
+		setup = append(setup, strings.Join(ti.GetPath(), "/"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(setup, " ") != "a a/a1 a/a2 b b/b1 c" {
+		t.Fatalf("setup called for %v", setup)
+	}
+
+	la := loaded.Items[0]
+	if !la.Open || loaded.Items[1].Open != b.Open || names(la.Children) != "a1,a2" {
+		t.Fatal("structure or open flags lost")
+	}
+	if srv, ok := la.Children[0].Data.(*server); !ok || *srv != (server{Host: "localhost", Port: 50000}) {
+		t.Fatalf("server data not restored: %#v", la.Children[0].Data)
+	}
+	if la.Children[1].Data != "note" {
+		t.Fatalf("string data not restored: %#v", la.Children[1].Data)
+	}
+	if la.Children[0].GetParent() != la || la.GetParent() != loaded || la.Children[0].parentTree != loaded {
+		t.Fatal("parent links not rebuilt")
+	}
+	if loaded.ActiveItem != la.Children[1] {
+		t.Fatal("active item not restored")
+	}
+	if loaded.FindByID("srv") != la.Children[0] {
+		t.Fatal("ID not restored")
+	}
+
+	if err := UnmarshalTree(data, loaded, NewTypeRegistry(), nil); err == nil {
+		t.Fatal("expected an error for unknown data types")
+	}
+}
@@ -0,0 +1,197 @@
+package teatree
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// LessFunc reports whether a should be listed before b.
+type LessFunc func(a, b *TreeItem) bool
+
+// SortMode is a named order that the Sort binding can switch to. A nil Less puts the items
+// back in the order they were added, and then leaves them wherever they are put, so that
+// they can be rearranged.
+type SortMode struct {
+	Name string
+	Less LessFunc
+}
+
+// DefaultSortModes returns the orders the Sort binding cycles through by default.
+func DefaultSortModes() []SortMode {
+	return []SortMode{
+		{Name: "insertion"},
+		{Name: "natural", Less: SortNatural},
+		{Name: "case-insensitive", Less: SortCaseInsensitive},
+		{Name: "directories first", Less: SortDirsFirst},
+		{Name: "reverse", Less: SortReverse(SortNatural)},
+	}
+}
+
+// insertSeq numbers items as they are added, so that the insertion order can be restored.
+var insertSeq atomic.Uint64
+
+// stamp records when the item was first added to a tree or item.
+func (ti *TreeItem) stamp() {
+	if ti.seq == 0 {
+		ti.seq = insertSeq.Add(1)
+	}
+}
+
+// SortInsertion lists items in the order they were added.
+func SortInsertion(a, b *TreeItem) bool {
+	return a.seq < b.seq
+}
+
+// SortNatural compares names with runs of digits taken as numbers, so "file2" comes before
+// "file10". Letters are compared without regard to case.
+func SortNatural(a, b *TreeItem) bool {
+	return naturalCompare(a.Name, b.Name) < 0
+}
+
+// SortCaseInsensitive compares names without regard to case.
+func SortCaseInsensitive(a, b *TreeItem) bool {
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// SortDirsFirst lists items that can have children before those that can't, each in
+// natural order.
+func SortDirsFirst(a, b *TreeItem) bool {
+	if a.CanHaveChildren != b.CanHaveChildren {
+		return a.CanHaveChildren
+	}
+	return SortNatural(a, b)
+}
+
+// SortReverse turns less around.
+func SortReverse(less LessFunc) LessFunc {
+	return func(a, b *TreeItem) bool {
+		return less(b, a)
+	}
+}
+
+// naturalCompare compares a and b a chunk at a time, where a chunk is a run of digits or a
+// run of anything else.
+func naturalCompare(a, b string) int {
+	origA, origB := a, b
+	for a != "" && b != "" {
+		ca, cb := leadingChunk(a), leadingChunk(b)
+		a, b = a[len(ca):], b[len(cb):]
+		if isDigit(ca[0]) && isDigit(cb[0]) {
+			na, nb := strings.TrimLeft(ca, "0"), strings.TrimLeft(cb, "0")
+			if c := cmp.Compare(len(na), len(nb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := strings.Compare(strings.ToLower(ca), strings.ToLower(cb)); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(origA, origB)
+}
+
+func leadingChunk(s string) string {
+	digits := isDigit(s[0])
+	x := 1
+	for x < len(s) && isDigit(s[x]) == digits {
+		x++
+	}
+	return s[:x]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lessFor returns the order for the children of h: its own, or else its tree's.
+func lessFor(h ItemHolder) LessFunc {
+	if ti, ok := h.(*TreeItem); ok && ti.less != nil {
+		return ti.less
+	}
+	if t := holderTree(h); t != nil {
+		return t.less
+	}
+	return nil
+}
+
+// sortChildren puts the children of h in order. Items that compare equal keep their places.
+func sortChildren(h ItemHolder) {
+	less := lessFor(h)
+	if less == nil {
+		return
+	}
+	slices.SortStableFunc(*childList(h), func(a, b *TreeItem) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+}
+
+// sortTree puts the children of h, and everything below them, in order.
+func sortTree(h ItemHolder) {
+	sortChildren(h)
+	for _, child := range *childList(h) {
+		sortTree(child)
+	}
+}
+
+// SetLess sets the order of the items in the tree, and sorts them. Items added later are
+// put in order as they arrive. A nil less leaves items where they are added.
+func (t *Tree) SetLess(less LessFunc) {
+	t.keepCursor(func() {
+		t.less = less
+		sortTree(t)
+	})
+}
+
+// CycleSort switches to the next of the tree's SortModes, and returns its name.
+func (t *Tree) CycleSort() string {
+	if len(t.SortModes) == 0 {
+		return ""
+	}
+	t.sortMode = (t.sortMode + 1) % len(t.SortModes)
+	mode := t.SortModes[t.sortMode]
+	t.useSortMode(mode)
+	return mode.Name
+}
+
+// useSortMode sorts the tree by mode.
+func (t *Tree) useSortMode(mode SortMode) {
+	if mode.Less == nil {
+		t.SetLess(SortInsertion)
+	}
+	t.SetLess(mode.Less)
+}
+
+// SetSortMode switches to the named entry in SortModes. It reports whether there was one.
+func (t *Tree) SetSortMode(name string) bool {
+	for x, mode := range t.SortModes {
+		if mode.Name == name {
+			t.sortMode = x
+			t.useSortMode(mode)
+			return true
+		}
+	}
+	return false
+}
+
+// SetLess sets the order of this item's children, overriding the tree's. It applies to the
+// direct children only.
+func (ti *TreeItem) SetLess(less LessFunc) {
+	ti.parentTree.keepCursor(func() {
+		ti.less = less
+		sortChildren(ti)
+	})
+}
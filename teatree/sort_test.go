@@ -0,0 +1,132 @@
+package teatree
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newNamedItems(names ...string) []*TreeItem {
+	var items []*TreeItem
+	for _, name := range names {
+		items = append(items, NewItem(name, false, nil, nil, nil, nil, nil, nil, nil))
+	}
+	return items
+}
+
+func TestNaturalCompare(t *testing.T) {
+	for _, tc := range []struct{ a, b string }{
+		{"file2", "file10"},
+		{"a", "B"},
+		{"x1y", "x1z"},
+		{"file", "file1"},
+		{"v1.9", "v1.10"},
+		{"007", "8"},
+	} {
+		if naturalCompare(tc.a, tc.b) >= 0 || naturalCompare(tc.b, tc.a) <= 0 {
+			t.Fatalf("expected %q before %q", tc.a, tc.b)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	tr := New().(*Tree)
+	tr.Height = 10
+	tr.AddChildren(newNamedItems("file10", "File2", "b", "file1")...)
+	dir := NewItem("zdir", true, nil, nil, nil, nil, nil, nil, nil)
+	tr.AddChildren(dir)
+	tr.SetActive(tr.Items[1])
+
+	tr.SetLess(SortNatural)
+	if names(tr.Items) != "b,file1,File2,file10,zdir" || tr.ActiveItem.Name != "File2" {
+		t.Fatalf("natural: %s", names(tr.Items))
+	}
+	tr.SetLess(SortDirsFirst)
+	if names(tr.Items) != "zdir,b,file1,File2,file10" {
+		t.Fatalf("dirs first: %s", names(tr.Items))
+	}
+	tr.SetLess(SortReverse(SortCaseInsensitive))
+	if names(tr.Items) != "zdir,File2,file10,file1,b" {
+		t.Fatalf("reverse: %s", names(tr.Items))
+	}
+	tr.SetLess(SortInsertion)
+	if names(tr.Items) != "file10,File2,b,file1,zdir" {
+		t.Fatalf("insertion: %s", names(tr.Items))
+	}
+
+	// New items go into place, and an item's own order wins over the tree's
+	tr.SetLess(SortNatural)
+	tr.AddChildren(newNamedItems("a")...)
+	dir.SetLess(SortReverse(SortNatural))
+	dir.AddChildren(newNamedItems("x", "z", "y")...)
+	if names(tr.Items) != "a,b,file1,File2,file10,zdir" || names(dir.Children) != "z,y,x" {
+		t.Fatalf("added: %s / %s", names(tr.Items), names(dir.Children))
+	}
+}
+
+func TestSortAfterLoad(t *testing.T) {
+	tr, dir := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		return newNamedItems("c", "a", "b"), nil
+	})
+	tr.SetLess(SortNatural)
+	_, cmd := tr.Update(keyMsg(" "))
+	for _, msg := range run(cmd) {
+		tr.Update(msg)
+	}
+	if names(dir.Children) != "a,b,c" {
+		t.Fatalf("loaded children not sorted: %s", names(dir.Children))
+	}
+}
+
+func TestCycleSort(t *testing.T) {
+	tr := New().(*Tree)
+	tr.AddChildren(newNamedItems("b", "a")...)
+	_, cmd := tr.Update(keyMsg("s"))
+	if names(tr.Items) != "a,b" {
+		t.Fatalf("expected natural order, got %s", names(tr.Items))
+	}
+	if msgs := run(cmd); len(msgs) != 1 || msgs[0].(SortedMsg).Mode != "natural" {
+		t.Fatalf("expected a SortedMsg, got %#v", msgs)
+	}
+	if got := tr.statusLine(); !strings.HasSuffix(got, "sorted by natural") {
+		t.Fatalf("sort order not shown: got %q", got)
+	}
+	for x := 1; x < len(tr.SortModes); x++ {
+		tr.Update(keyMsg("s"))
+	}
+	if names(tr.Items) != "b,a" || tr.SortModes[tr.sortMode].Name != "insertion" {
+		t.Fatalf("expected to cycle back to insertion order, got %s", names(tr.Items))
+	}
+	if !tr.SetSortMode("reverse") || names(tr.Items) != "b,a" || tr.SetSortMode("nope") {
+		t.Fatal("SetSortMode")
+	}
+}
+
+// Items go where they are put in insertion order, and can't be reordered in any other.
+func TestSortExplicitPositions(t *testing.T) {
+	tr := New().(*Tree)
+	tr.UndoLimit = DefaultUndoLimit
+	tr.AllowMoves = true
+	tr.AddChildren(newNamedItems("b", "a", "c")...)
+	tr.SetSortMode("natural")
+	tr.SetSortMode("insertion")
+	if names(tr.Items) != "b,a,c" {
+		t.Fatalf("expected the insertion order back, got %s", names(tr.Items))
+	}
+	tr.InsertAt(0, newNamedItems("z")...)
+	if names(tr.Items) != "z,b,a,c" {
+		t.Fatalf("InsertAt(0) in insertion order: got %s", names(tr.Items))
+	}
+
+	tr.SetSortMode("natural")
+	tr.ClearUndo()
+	tr.SetActive(tr.Items[0])
+	tr.Cut()
+	tr.SetActive(tr.Items[2])
+	if err := tr.Paste(false); err != ErrSorted {
+		t.Fatalf("expected ErrSorted, got %v", err)
+	}
+	if tr.CanUndo() {
+		t.Fatal("a refused paste should not be recorded")
+	}
+}
@@ -0,0 +1,131 @@
+package teatree
+
+import (
+	"encoding/json"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TreeState is a snapshot of which items are open, where the cursor is and how far the view
+// is scrolled. Items are identified by their paths, so a state can be saved and applied to a
+// tree that has been built again from scratch.
+type TreeState struct {
+	Open    [][]string `json:"open,omitempty"`
+	Active  []string   `json:"active,omitempty"`
+	Viewtop int        `json:"viewtop,omitempty"`
+}
+
+// State takes a snapshot of the tree. The open paths are listed parents first.
+func (t *Tree) State() TreeState {
+	var s TreeState
+	forEachItem(t.Items, func(ti *TreeItem) {
+		if ti.Open && ti.CanHaveChildren && !ti.placeholder {
+			s.Open = append(s.Open, ti.GetPath())
+		}
+	})
+	s.Active = t.activePath()
+	s.Viewtop = t.Viewtop
+	return s
+}
+
+// Marshal returns the state as JSON.
+func (s TreeState) Marshal() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// UnmarshalState reads a state saved by TreeState.Marshal.
+func UnmarshalState(data []byte) (TreeState, error) {
+	var s TreeState
+	err := json.Unmarshal(data, &s)
+	return s, err
+}
+
+// Save writes the state to the file name.
+func (s TreeState) Save(name string) error {
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+// LoadState reads a state written by TreeState.Save.
+func LoadState(name string) (TreeState, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return TreeState{}, err
+	}
+	return UnmarshalState(data)
+}
+
+// ApplyState opens the items that were open in s, and puts the cursor and the view back
+// where they were. Items are opened with ToggleChildren, so their OpenFuncs and LoadFuncs
+// run. Paths below an item that is still loading are applied when its children arrive, so
+// the returned command must be run for the state to be fully restored. Paths that no longer
+// exist are skipped; if the active item has gone, the cursor goes to its closest ancestor.
+func (t *Tree) ApplyState(s TreeState) tea.Cmd {
+	t.pendingState = &s
+	t.applyPending()
+	return t.takeCmds(nil)
+}
+
+// applyPending applies as much of the pending state as the loaded items allow.
+func (t *Tree) applyPending() {
+	s := t.pendingState
+	if s == nil {
+		return
+	}
+
+	var waiting [][]string
+	for _, p := range s.Open {
+		ti, found, wait := t.resolvePath(p)
+		switch {
+		case found:
+			if !ti.Open {
+				ti.ToggleChildren()
+			}
+		case wait:
+			waiting = append(waiting, p)
+		}
+	}
+	s.Open = waiting
+
+	if s.Active != nil {
+		ti, _, wait := t.resolvePath(s.Active)
+		if !wait {
+			if ti != nil {
+				t.SetActive(ti)
+			}
+			t.Viewtop = s.Viewtop
+			s.Active = nil
+		}
+	}
+	t.Invalidate()
+	t.scrollToActive()
+
+	if len(s.Open) == 0 && s.Active == nil {
+		t.pendingState = nil
+	}
+}
+
+// resolvePath follows path down from the top of the tree. It returns the deepest item it
+// reached, whether that is the whole path, and whether the rest of the path may still turn
+// up because that item's children are being loaded.
+func (t *Tree) resolvePath(path []string) (ti *TreeItem, found, wait bool) {
+	items := t.Items
+	for _, name := range path {
+		var next *TreeItem
+		for _, item := range items {
+			if item.Name == name && !item.placeholder {
+				next = item
+				break
+			}
+		}
+		if next == nil {
+			return ti, false, ti != nil && ti.Loading()
+		}
+		ti, items = next, next.Children
+	}
+	return ti, ti != nil, false
+}
@@ -0,0 +1,66 @@
+package teatree
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	tr := newTestTree(2)
+	tr.Items[0].OpenChildren()
+	tr.Items[1].OpenChildren()
+	tr.SetActive(tr.Items[1].Children[0])
+	tr.scrollToActive()
+	state := tr.State()
+	if len(state.Open) != 2 || strings.Join(state.Active, "/") != "b/b1" || state.Viewtop != 3 {
+		t.Fatalf("bad snapshot: %+v", state)
+	}
+
+	name := filepath.Join(t.TempDir(), "state.json")
+	if err := state.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := newTestTree(2)
+	fresh.ApplyState(loaded)
+	if !fresh.Items[0].Open || !fresh.Items[1].Open || fresh.Items[2].Open {
+		t.Fatal("open flags not restored")
+	}
+	if fresh.ActiveItem != fresh.Items[1].Children[0] || fresh.Viewtop != 3 || fresh.ActiveLine != 1 {
+		t.Fatalf("cursor not restored: %s top %d line %d", fresh.ActiveItem.Name, fresh.Viewtop, fresh.ActiveLine)
+	}
+
+	// The active item has gone, so the cursor settles on its parent
+	fresh = newTestTree(2)
+	fresh.Items[1].ReplaceChildren()
+	fresh.ApplyState(loaded)
+	if fresh.ActiveItem != fresh.Items[1] {
+		t.Fatalf("expected the cursor on b, got %s", fresh.ActiveItem.Name)
+	}
+}
+
+func TestStateWaitsForLoads(t *testing.T) {
+	tr, _ := newLazyTree(func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+		sub := NewItem("sub", true, nil, nil, nil, nil, nil, nil, nil)
+		sub.LoadFunc = func(ctx context.Context, ti *TreeItem) ([]*TreeItem, error) {
+			return []*TreeItem{NewItem("file", false, nil, nil, nil, nil, nil, nil, nil)}, nil
+		}
+		return []*TreeItem{sub}, nil
+	})
+	state := TreeState{Open: [][]string{{"dir"}, {"dir", "sub"}}, Active: []string{"dir", "sub", "file"}}
+
+	msgs := run(tr.ApplyState(state))
+	for len(msgs) > 0 {
+		_, cmd := tr.Update(msgs[0])
+		msgs = append(msgs[1:], run(cmd)...)
+	}
+	if strings.Join(tr.activePath(), "/") != "dir/sub/file" || tr.pendingState != nil {
+		t.Fatalf("state not fully applied, active %v", tr.activePath())
+	}
+}
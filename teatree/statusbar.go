@@ -0,0 +1,90 @@
+package teatree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reportError keeps err to be shown on the status line until the next key press.
+func (t *Tree) reportError(err error) {
+	if err != nil {
+		t.lastErr = err
+	}
+}
+
+// LastError returns the error shown on the status line: the last one from a load, a move or
+// the field's validation.
+func (t *Tree) LastError() error {
+	if t.lastErr != nil {
+		return t.lastErr
+	}
+	return t.err
+}
+
+// breadcrumb draws the path of the active item, with the Separator glyph between its parts.
+// If it is too wide, parts are taken out of the middle and replaced with the Ellipsis, so
+// that the top of the tree and the item itself stay in view.
+func (t *Tree) breadcrumb() string {
+	if t.ActiveItem == nil {
+		return ""
+	}
+	path := t.ActiveItem.GetPath()
+	sep := t.Styles.Guide.Render(t.Glyphs.Separator)
+	render := func(parts []string) string {
+		out := make([]string, len(parts))
+		for x, part := range parts {
+			style := t.Styles.Breadcrumb
+			if x == len(parts)-1 {
+				style = style.Bold(true)
+			}
+			out[x] = style.Render(part)
+		}
+		return strings.Join(out, sep)
+	}
+
+	width := t.innerWidth()
+	crumb := render(path)
+	if width <= 0 || lipgloss.Width(crumb) <= width || len(path) < 3 {
+		return t.clip(crumb, width)
+	}
+	// Keep the first part, and as many from the end as fit after the ellipsis
+	for drop := 1; drop < len(path)-1; drop++ {
+		parts := append([]string{path[0], t.Ellipsis}, path[1+drop:]...)
+		if crumb = render(parts); lipgloss.Width(crumb) <= width {
+			return crumb
+		}
+	}
+	return t.clip(crumb, width)
+}
+
+// statusLine draws the position of the cursor, the number of children of the active item,
+// the order the Sort binding last switched to, and the last error.
+func (t *Tree) statusLine() string {
+	var parts []string
+	if ti := t.ActiveItem; ti != nil {
+		parts = append(parts, fmt.Sprintf("%d/%d", t.rowOf(ti)+1, len(t.visibleRows())))
+		switch {
+		case ti.Loading():
+			parts = append(parts, LoadingLabel)
+		case ti.lazy() && !ti.loaded:
+			// Not known until the item is opened
+		case ti.CanHaveChildren && len(ti.Children) == 1:
+			parts = append(parts, "1 child")
+		case ti.CanHaveChildren:
+			parts = append(parts, fmt.Sprintf("%d children", len(ti.Children)))
+		}
+	}
+	if t.notice != "" {
+		parts = append(parts, t.notice)
+	}
+	line := t.Styles.Status.Render(strings.Join(parts, " · "))
+	if err := t.LastError(); err != nil {
+		if line != "" {
+			line += t.Styles.Status.Render(" · ")
+		}
+		line += t.Styles.Error.Render(err.Error())
+	}
+	return t.clip(line, t.innerWidth())
+}
@@ -0,0 +1,53 @@
+package teatree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBreadcrumb(t *testing.T) {
+	tr := New().(*Tree)
+	tr.Glyphs = ASCIIGlyphs
+	tr.ShowBreadcrumb = true
+	var h ItemHolder = tr
+	for _, name := range []string{"usr", "local", "share", "doc", "readme"} {
+		item := NewItem(name, true, nil, nil, nil, nil, nil, nil, nil)
+		h.AddChildren(item)
+		item.Open = true
+		h = item
+	}
+	tr.Invalidate()
+	tr.SetActive(h.(*TreeItem))
+
+	if got := strings.Split(tr.View(), "\n")[0]; got != "usr > local > share > doc > readme" {
+		t.Fatalf("breadcrumb: got %q", got)
+	}
+	tr.Width = 24
+	if got := strings.Split(tr.View(), "\n")[0]; got != "usr > … > doc > readme" {
+		t.Fatalf("collapsed breadcrumb: got %q", got)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	tr := newTestTree(5)
+	tr.ShowStatus = true
+	tr.ShowBreadcrumb = true
+	tr.Items[0].OpenChildren()
+	tr.Items[1].OpenChildren()
+
+	// The breadcrumb and status line come out of the 5 lines, leaving 3 for rows
+	lines := strings.Split(tr.View(), "\n")
+	if len(lines) != 5 || lines[0] != "a" || lines[4] != "1/6 · 2 children" {
+		t.Fatalf("got:\n%s", strings.Join(lines, "\n"))
+	}
+
+	tr.reportError(errors.New("boom"))
+	if got := tr.statusLine(); got != "1/6 · 2 children · boom" {
+		t.Fatalf("error not shown: got %q", got)
+	}
+	tr.Update(keyMsg("j"))
+	if got := tr.statusLine(); got != "2/6" {
+		t.Fatalf("error should clear on the next key: got %q", got)
+	}
+}
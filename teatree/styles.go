@@ -0,0 +1,134 @@
+package teatree
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles holds the styles a tree is drawn with. Each tree has its own, so that trees on the
+// same screen can look different.
+type Styles struct {
+	Cursor        lipgloss.Style // The active row, while the tree has focus
+	BlurredCursor lipgloss.Style // The active row, while it doesn't
+	Selected      lipgloss.Style // Checked rows
+	Unselected    lipgloss.Style // Every other row
+	Disabled      lipgloss.Style // Rows of disabled items
+	Marked        lipgloss.Style // Items that have been cut, waiting to be pasted
+	Chevron       lipgloss.Style
+	Guide         lipgloss.Style // Indentation and guide lines
+	SearchMatch   lipgloss.Style // The part of a label that matches the search
+	SearchInfo    lipgloss.Style // The match count on the search line
+	Header        lipgloss.Style // Column titles
+	Status        lipgloss.Style // The active label and status lines
+	Breadcrumb    lipgloss.Style // The parts of the path in the breadcrumb
+	Help          help.Styles
+	LoadError     lipgloss.Style // Rows standing in for children that failed to load
+	Error         lipgloss.Style // Inline errors, such as a rejected rename
+}
+
+// DefaultStyles returns the styles a new tree uses.
+func DefaultStyles() Styles {
+	return Styles{
+		Cursor: lipgloss.NewStyle().
+			Background(lipgloss.Color("62")),
+		BlurredCursor: lipgloss.NewStyle().
+			Background(lipgloss.Color("238")),
+		Selected:   lipgloss.NewStyle(),
+		Unselected: lipgloss.NewStyle(),
+		Disabled: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")),
+		Marked: lipgloss.NewStyle().
+			Italic(true).
+			Faint(true),
+		Chevron: lipgloss.NewStyle(),
+		Guide: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")),
+		SearchMatch: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("220")),
+		SearchInfo: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")),
+		Header: lipgloss.NewStyle().
+			Bold(true).
+			Faint(true),
+		Status: lipgloss.NewStyle().
+			Faint(true),
+		Breadcrumb: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("249")),
+		Help: help.New().Styles,
+		LoadError: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")),
+		Error: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")),
+	}
+}
+
+// StylesFromTheme maps the colours of a huh theme, such as huh.ThemeCharm, ThemeDracula,
+// ThemeCatppuccin or ThemeBase16, onto a tree, so that it matches the form around it.
+func StylesFromTheme(theme *huh.Theme) Styles {
+	f, b := theme.Focused, theme.Blurred
+	return Styles{
+		Cursor: lipgloss.NewStyle().
+			Foreground(f.FocusedButton.GetForeground()).
+			Background(f.FocusedButton.GetBackground()),
+		BlurredCursor: lipgloss.NewStyle().
+			Foreground(b.BlurredButton.GetForeground()).
+			Background(b.BlurredButton.GetBackground()),
+		Selected: lipgloss.NewStyle().
+			Foreground(f.SelectedOption.GetForeground()),
+		Unselected: lipgloss.NewStyle().
+			Foreground(f.UnselectedOption.GetForeground()),
+		Disabled: lipgloss.NewStyle().
+			Foreground(f.TextInput.Placeholder.GetForeground()),
+		Marked: lipgloss.NewStyle().
+			Foreground(b.Title.GetForeground()).
+			Italic(true),
+		Chevron: lipgloss.NewStyle().
+			Foreground(f.SelectSelector.GetForeground()),
+		Guide: lipgloss.NewStyle().
+			Foreground(b.Description.GetForeground()),
+		SearchMatch: lipgloss.NewStyle().
+			Foreground(f.Title.GetForeground()).
+			Underline(true),
+		SearchInfo: lipgloss.NewStyle().
+			Foreground(f.Description.GetForeground()),
+		Header: lipgloss.NewStyle().
+			Foreground(f.Title.GetForeground()).
+			Bold(true),
+		Status: lipgloss.NewStyle().
+			Foreground(f.Description.GetForeground()),
+		Breadcrumb: lipgloss.NewStyle().
+			Foreground(f.Title.GetForeground()),
+		Help: theme.Help,
+		LoadError: lipgloss.NewStyle().
+			Foreground(f.ErrorMessage.GetForeground()),
+		Error: lipgloss.NewStyle().
+			Foreground(f.ErrorMessage.GetForeground()),
+	}
+}
+
+// SetStyles sets the tree's styles, and keeps them when the tree is given a theme by a form.
+func (t *Tree) SetStyles(styles Styles) *Tree {
+	t.Styles = styles
+	t.ownStyles = true
+	return t
+}
+
+// rowStyle returns the style a row is drawn with, before the item's own label and icon
+// styles are applied.
+func (t *Tree) rowStyle(ti *TreeItem) lipgloss.Style {
+	switch {
+	case t.ActiveItem == ti && t.focused:
+		return t.Styles.Cursor
+	case t.ActiveItem == ti:
+		return t.Styles.BlurredCursor
+	case ti.Disabled:
+		return t.Styles.Disabled
+	case t.marked(ti):
+		return t.Styles.Marked
+	case ti.Checked && t.SelectionMode != SelectSingle:
+		return t.Styles.Selected
+	}
+	return t.Styles.Unselected
+}
@@ -0,0 +1,58 @@
+package teatree
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestStylesFromTheme(t *testing.T) {
+	a, b := newTestTree(10), newTestTree(10)
+	theme := huh.ThemeDracula()
+	b.WithTheme(theme)
+	if a.Styles.Cursor.GetBackground() != DefaultStyles().Cursor.GetBackground() {
+		t.Fatal("a tree without a theme should keep the default styles")
+	}
+	if got, want := b.Styles.Cursor.GetBackground(), theme.Focused.FocusedButton.GetBackground(); got != want {
+		t.Fatalf("cursor background: got %v, want %v", got, want)
+	}
+
+	own := DefaultStyles()
+	own.Cursor = own.Cursor.Background(lipgloss.Color("1"))
+	a.SetStyles(own).WithTheme(theme)
+	if got := a.Styles.Cursor.GetBackground(); got != lipgloss.Color("1") {
+		t.Fatalf("styles set with SetStyles were replaced by the theme: got %v", got)
+	}
+}
+
+func TestDisabledItem(t *testing.T) {
+	tr := newTestTree(10)
+	tr.SelectionMode = SelectMulti
+	c := tr.Items[2]
+	c.Disabled = true
+	tr.SetActive(c)
+
+	_, cmd := tr.Update(keyMsg("enter"))
+	for _, msg := range run(cmd) {
+		if _, ok := msg.(SelectedMsg); ok {
+			t.Fatal("a disabled item should not be selectable")
+		}
+	}
+	tr.ToggleChecked(c)
+	if c.Checked {
+		t.Fatal("a disabled item should not be checkable")
+	}
+}
+
+// A tree used on its own draws its cursor, but one in a form waits for the form to focus it.
+func TestCursorFocus(t *testing.T) {
+	tr := newTestTree(10)
+	if tr.rowStyle(tr.Items[0]).GetBackground() != tr.Styles.Cursor.GetBackground() {
+		t.Fatal("a new tree should draw the cursor")
+	}
+	huh.NewForm(huh.NewGroup(tr))
+	if tr.Focused() {
+		t.Fatal("a form should blur the tree until its turn comes")
+	}
+}
@@ -1,12 +1,15 @@
 package teatree
 
 import (
-	"encoding/json"
+	"context"
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -26,44 +29,47 @@ type ItemHolder interface {
 	AddChildren(...*TreeItem) ItemHolder
 	GetParent() ItemHolder
 	Refresh() // This tells the item holder to delete all of its children and re-read them.
+	// RemoveItem, InsertAt and ReplaceChildren change the children of the holder, keeping the
+	// parent links and the tree's cursor consistent.
+	RemoveItem(*TreeItem) bool
+	InsertAt(int, ...*TreeItem) ItemHolder
+	ReplaceChildren(...*TreeItem) ItemHolder
 }
 
-// Styling
-
-var (
-	unfocusedStyle = lipgloss.NewStyle()
-	// Border(lipgloss.HiddenBorder()).
-	// BorderTop(false).
-	// BorderBottom(false)
-	focusedStyle = lipgloss.NewStyle().
-		//Border(lipgloss.RoundedBorder()).
-		//BorderTop(false).
-		//BorderBottom(false).
-		Background(lipgloss.Color("62")).
-		BorderForeground(lipgloss.Color("62"))
-	//Background(lipgloss.Color("#FFFFFF"))
-)
-
 type TreeItem struct {
+	// Only taken by AddChildren, and kept for callers that lock it themselves. It doesn't
+	// make the item safe to share between goroutines; see Post.
 	sync.Mutex
 	parentTree      *Tree      `json:"-"`
 	parent          ItemHolder `json:"-"`
 	Name            string
+	ID              string // Optional, and unique within the tree. Set it before adding the item, or with SetID
 	Children        []*TreeItem
 	CanHaveChildren bool // CanHaveChildren: By setting this to True, you say that this item can have children. This allows for the implementation of a lazy loader, when you supply an Open() function. This affects how the item is rendered.
 	Open            bool
+	Checked         bool // Only used when the tree's SelectionMode is SelectMulti or SelectCascade. Use SetChecked once the item is in a tree
+	Disabled        bool // Drawn greyed out, and can't be selected or checked
 	Data            interface{}
 	OpenFunc        func(*TreeItem)                `json:"-"`
 	CloseFunc       func(*TreeItem)                `json:"-"`
+	LoadFunc        LoadFunc                       `json:"-"` // Fetches the children in the background the first time the item is opened
+	LoadCmd         LoadCmd                        `json:"-"` // Used instead of LoadFunc, for loaders written as commands
 	icon            func(*TreeItem) string         // Function returns what the icon should be.
 	labelStyle      func(*TreeItem) lipgloss.Style // Function returns the style for the label, intended for color
 	iconStyle       func(*TreeItem) lipgloss.Style // Function returns the style for the icon, intended for color
-	entering        func(*TreeItem)                // Called when the user selects the item
-	exiting         func(*TreeItem)                // Called when the user deselects the item
 	selectFunc      func(*TreeItem)
-	indent          int
-}
-
+	cancelLoad      context.CancelFunc // Set while a LoadFunc is running
+	loadID          int
+	loaded          bool
+	placeholder     bool     // A loading or error row standing in for the real children
+	checkedBelow    int      // How many descendants are checked, see addChecked
+	less            LessFunc // Overrides the tree's order for this item's children
+	seq             uint64   // When the item was first added, for SortInsertion
+}
+
+// SetSelectFunc sets a function to call when the user selects this item.
+//
+// Deprecated: handle SelectedMsg in the host model's Update instead.
 func (ti *TreeItem) SetSelectFunc(sf func(*TreeItem)) {
 	ti.selectFunc = sf
 }
@@ -95,9 +101,12 @@ func (ti *TreeItem) GetParent() ItemHolder {
 func (ti *TreeItem) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch tmsg := msg.(type) {
 	case tea.KeyMsg:
-		switch tmsg.String() {
-		case "enter":
-			log.Println("-- at TreeItem.Update(), user hit enter")
+		switch {
+		case !ti.placeholder && !ti.Disabled && ti.parentTree != nil && ti.parentTree.rowOf(ti) >= 0 &&
+			key.Matches(tmsg, ti.parentTree.KeyMap.Select):
+			log.Println("-- at TreeItem.Update(), user hit select")
+			t := ti.parentTree
+			t.emit(SelectedMsg{Tree: t, Item: ti, Path: ti.GetPath()})
 			if ti.selectFunc != nil {
 				log.Println("-- selectFunc not nil, executing")
 				ti.selectFunc(ti)
@@ -113,141 +122,50 @@ func (ti *TreeItem) Init() tea.Cmd {
 	return nil
 }
 
+// Refresh closes the item and throws away its children, so that its LoadFunc fetches them
+// again the next time it is opened. A cursor below the item moves up to it.
 func (ti *TreeItem) Refresh() {
-	ti.Children = []*TreeItem{}
+	ti.cancelLoading()
+	ti.parentTree.keepCursor(func() {
+		replaceChildren(ti, nil)
+	})
 	ti.Open = false
+	ti.loaded = false
+	ti.invalidate()
 }
 
 func (ti *TreeItem) GetItems() []*TreeItem {
 	return ti.Children
 }
 
-// SelectPrevious - this is being invoked on a TreeItem that is currently selected and the
-// user wants to move up to the previous selection. This will involve recursively going up the
-// tree until we find the one to select or we get to the top
+// SelectPrevious moves the cursor from this item to the row above it.
 func (ti *TreeItem) SelectPrevious() {
-
-	// User has pressed 'up'. If we're at the top of the "view", then we want to try and scroll up by one
-	// We do this by checking the viewtop value. If it is > 0, then the view has scrolled down, so we
-	// can decrement it by one.
-
-	tree := ti.parentTree
-	atTop := false
-
-	if tree.ActiveLine == 0 {
-		atTop = true
-	} else {
-		tree.ActiveLine -= 1
-	}
-
-	siblingItems := ti.GetParent().GetItems()
-
-	for x, item := range siblingItems {
-		if item == ti {
-			// Check to see if there is a previous one in the current list
-			if x-1 >= 0 {
-				newItem := siblingItems[x-1]
-				// We want the next one "up". This could be the previous sibling, or if that sibling is open, it could be one of the siblings's children
-				for {
-					// Can this item have children?
-					if newItem.CanHaveChildren && newItem.Open && len(newItem.Children) > 0 {
-						// If so, we want to check again with the last one in this list. This could
-						// be a many level tree, and we always want to check the last unopened child
-						lastKid := len(newItem.Children) - 1
-						// Descend into this child and iterate through checking this one
-						newItem = newItem.Children[lastKid]
-					} else {
-						if atTop {
-							tree.ScrollUp(1)
-						}
-						item.parentTree.SetActive(newItem)
-						return
-					}
-
-					//item.parentTree.ActiveItem = siblingItems[x-1]
-				}
-			} else {
-				// Nope, we were at the top. So now we just activate our Parent
-				// Just make sure the parent is an item and not the tree. If it's the tree,
-				// then we stop
-				par, ok := ti.GetParent().(*TreeItem)
-				if ok {
-					if atTop {
-						tree.ScrollUp(1)
-					}
-					item.parentTree.SetActive(par)
-				}
-			}
-			return
-		}
+	if t := ti.parentTree; t != nil {
+		t.SetActive(ti)
+		t.moveActive(-1)
 	}
 }
 
-// We're being told to select the next item relative to our current position.
+// SelectNext moves the cursor from this item to the row below it.
 func (ti *TreeItem) SelectNext() {
-	// See if we need to scroll first:
-
-	tree := ti.parentTree
-
-	atBottom := false
-	windowBottom := tree.Height
-
-	if tree.ActiveLine == windowBottom-1 {
-		atBottom = true
-	} else {
-		tree.ActiveLine += 1
-	}
-	descend := true
-	for {
-		parentItems := ti.GetParent().GetItems()
-
-		// Check to see if we are going down into a child. If so, select the first child
-		if len(ti.Children) > 0 && ti.Open && descend {
-			ti.parentTree.SetActive(ti.Children[0])
-			if atBottom {
-				tree.ScrollDown(1)
-			}
-			return
-		}
-
-		// If we're not descending into one of our own children, then we are going to the next
-		// sibling item to us
-		for x, item := range parentItems {
-			// Find the current item
-			if item == ti {
-				if x+1 < len(parentItems) {
-					// Select the sibling if there is one
-					ti.parentTree.SetActive(parentItems[x+1])
-					if atBottom {
-						tree.ScrollDown(1)
-					}
-					// If there is no sibling, then we are at the end and just do nothing
-					return
-				}
-			}
-		}
-		if ti.parent == ti.parentTree {
-			// We've hit the top
-			return
-		}
-		// To get here, the user has tried to go past the end of the current list of children.
-		// So we now need to tell our parent to choose a sibling. We turn off descent because
-		// we aren't here going into an item, because we've already gone beyond the end of a list
-		descend = false
-		ti = ti.GetParent().(*TreeItem)
+	if t := ti.parentTree; t != nil {
+		t.SetActive(ti)
+		t.moveActive(1)
 	}
 }
 
 // SelectLast - starting from the current Item, descend in the last child of the last child and set
-// that as the active item. Also need to set the topline ff
+// that as the active item.
 func (ti *TreeItem) SelectLast() {
-	//numchildren := ti.parentTree.CountVisibleItems()
 	if ti.CanHaveChildren && ti.Open && len(ti.Children) > 0 {
 		ti.Children[len(ti.Children)-1].SelectLast()
 		return
 	}
 	// If I can't have any children, then I am the one to be selected
-	ti.parentTree.ActiveItem = ti
+	if t := ti.parentTree; t != nil {
+		t.SetActive(ti)
+		t.scrollToActive()
+	}
 }
 
 // CountItemAndChildren - returns the count of this item plus any visible children.
@@ -261,117 +179,26 @@ func (ti *TreeItem) CountItemAndChildren() int {
 	return total
 }
 
-func (ti *TreeItem) ViewScrolled(viewtop, curline, bottomline int) (int, string) {
-	// Return the view string for myself plus my children if I am open
-	var pre_s string
-	for x := 0; x < ti.indent; x++ {
-		pre_s += "  "
-	}
-	if ti.CanHaveChildren {
-		if ti.Open {
-			pre_s += ChevronDown
-		} else {
-			pre_s += ChevronRight
-		}
-	} else {
-		pre_s += NoChevron
-	}
-
-	ai := ti.parentTree.ActiveItem
-
-	var s string
-	render := true
-	if curline < 0 {
-		render = false
-	}
-
-	if render {
-		var baseline lipgloss.Style
-		if ai != nil && ai == ti {
-			baseline = focusedStyle
-		} else {
-			baseline = unfocusedStyle
-		}
-		istyle := baseline.Inherit(ti.IconStyle())
-		lstyle := baseline.Inherit(ti.LabelStyle())
-		s = pre_s + istyle.Render(s+ti.Icon()) + baseline.Render(" ") + lstyle.Render(ti.Name)
-	}
-
-	curline += 1
-
-	if curline == bottomline {
-		return curline, s
-	}
-
-	if len(ti.Children) > 0 && ti.Open {
-		var kids []string
-		for _, item := range ti.Children {
-			item.indent = ti.indent + 1
-			var tmps string
-			curline, tmps = item.ViewScrolled(viewtop, curline, bottomline)
-			if strings.TrimSpace(tmps) != "" {
-				kids = append(kids, tmps)
-			}
-			if curline >= bottomline {
-				break
-			}
-		}
-		var composite []string
-		if s != "" {
-			composite = append(composite, s)
-		}
-
-		composite = append(composite, kids...)
-		s = lipgloss.JoinVertical(
-			lipgloss.Left,
-			composite...,
-		)
-	}
-	return curline, s
-}
-
+// View draws the item's own row. The children are drawn by the tree, as rows of their own.
 func (ti *TreeItem) View() string {
-	// Return the view string for myself plus my children if I am open
-	var s string
-	for x := 0; x < ti.indent; x++ {
-		s += "  "
-	}
-	if ti.CanHaveChildren {
-		if ti.Open {
-			s += ChevronDown
-		} else {
-			s += ChevronRight
-		}
-	} else {
-		s += NoChevron
+	t := ti.parentTree
+	if t == nil {
+		// Not part of a tree, so draw it with the defaults
+		return strings.Repeat(" ", ti.depth()*DefaultIndentWidth) + NerdFontGlyphs.chevron(ti) + ti.Icon() + " " + ti.Name
 	}
-
-	ai := ti.parentTree.ActiveItem
-
-	if ai != nil && ai == ti {
-		// If this is the active item, then we should be highlit
-		s = focusedStyle.Render(s + ti.Icon() + " " + ti.Name)
-	} else {
-		//s += ti.Icon + " " + ti.Name
-		s = unfocusedStyle.Render(s + ti.Icon() + " " + ti.Name)
+	if x := t.rowOf(ti); x >= 0 {
+		return t.renderRow(t.rows[x])
 	}
+	return t.renderRow(row{item: ti, depth: ti.depth(), last: true})
+}
 
-	if len(ti.Children) > 0 && ti.Open {
-		var kids []string
-		for _, item := range ti.Children {
-			item.indent = ti.indent + 1
-			inners := ""
-			inners += item.View()
-			kids = append(kids, inners)
-		}
-		composite := []string{s}
-		composite = append(composite, kids...)
-		s = lipgloss.JoinVertical(
-			lipgloss.Left,
-			composite...,
-		)
+// depth returns how many ancestors the item has.
+func (ti *TreeItem) depth() int {
+	depth := 0
+	for par, ok := ti.GetParent().(*TreeItem); ok; par, ok = par.GetParent().(*TreeItem) {
+		depth++
 	}
-	return s
+	return depth
 }
 
 func (ti *TreeItem) GetPath() []string {
@@ -384,20 +211,28 @@ func (ti *TreeItem) GetPath() []string {
 
 func (ti *TreeItem) OpenChildren() {
 	ti.Open = true
+	ti.invalidate()
 }
 
 func (ti *TreeItem) CloseChildren() {
 	ti.Open = false
+	ti.invalidate()
 }
 
 func (ti *TreeItem) ToggleChildren() {
 	if ti.CanHaveChildren {
 		ti.Open = !ti.Open
+		ti.invalidate()
+		ti.emitToggled()
 		if ti.Open {
 			if ti.OpenFunc != nil {
 				ti.OpenFunc(ti)
 			}
+			if ti.lazy() && !ti.loaded && !ti.Loading() {
+				ti.startLoad()
+			}
 		} else {
+			ti.cancelLoading()
 			if ti.CloseFunc != nil {
 				ti.CloseFunc(ti)
 			}
@@ -409,18 +244,22 @@ func (ti *TreeItem) ToggleChildren() {
 // AddChild - adds a child item to the item. Adding a child will result in the automatic inclusion of
 // the collapse chevron
 func (ti *TreeItem) AddChildren(children ...*TreeItem) ItemHolder {
-	// TODO: Should this do any mutex
+	// If CanHaveChildren wasn't set before, it will be now
 	ti.Lock()
-	ti.Children = append(ti.Children, children...)
+	insertRecorded(ti, len(ti.Children), children)
 	ti.Unlock()
-	ti.CanHaveChildren = true // If it wasn't set before, it will be now
+	ti.invalidate()
+	return ti
+}
 
-	for _, child := range children {
-		child.parent = ti
-		child.parentTree = ti.parentTree
+// setTree records which tree the item, and everything below it, belongs to.
+func (ti *TreeItem) setTree(t *Tree) {
+	ti.parentTree.unindex(ti)
+	ti.parentTree = t
+	t.index(ti)
+	for _, child := range ti.Children {
+		child.setTree(t)
 	}
-
-	return ti
 }
 
 func NewItem(name string, canHaveChildren bool, children []*TreeItem, icon func(*TreeItem) string, labelStyle, iconStyle func(*TreeItem) lipgloss.Style, openFunc, closeFunc func(*TreeItem), data interface{}) *TreeItem {
@@ -438,6 +277,8 @@ func NewItem(name string, canHaveChildren bool, children []*TreeItem, icon func(
 	}
 }
 
+// KeyMap holds every key binding the Tree responds to. Replace individual bindings on
+// Tree.KeyMap to remap them.
 type KeyMap struct {
 	Space    key.Binding
 	GoToTop  key.Binding
@@ -449,106 +290,143 @@ type KeyMap struct {
 	Back     key.Binding
 	Open     key.Binding
 	Select   key.Binding
+	Check    key.Binding
+	Sort     key.Binding
+
+	ScrollLeft  key.Binding
+	ScrollRight key.Binding
+
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
+	AcceptSearch key.Binding
+	CancelSearch key.Binding
+
+	Rename       key.Binding
+	AcceptRename key.Binding
+	CancelRename key.Binding
+
+	Cut         key.Binding
+	PasteAfter  key.Binding
+	PasteInside key.Binding
+
+	Undo key.Binding
+	Redo key.Binding
+
+	Help key.Binding
+
+	// Vim style fold commands, typed after the Fold prefix
+	Fold        key.Binding
+	ExpandAll   key.Binding
+	CollapseAll key.Binding
+	OpenFold    key.Binding
+	CloseFold   key.Binding
+	OpenFoldAll key.Binding
 }
 
 type Tree struct {
+	// Only taken by AddChildren, and kept for callers that lock it themselves. It doesn't
+	// make the tree safe to share between goroutines; see Post.
 	sync.Mutex
 	Viewtop              int // for scrolling
+	Viewleft             int // Cells scrolled off the left of the rows
 	Width                int
 	Height               int
-	ClosedChildrenSymbol string
-	OpenChildrenSymbol   string
+	ClosedChildrenSymbol string // Overrides the Closed glyph if set
+	OpenChildrenSymbol   string // Overrides the Open glyph if set
+	Glyphs               GlyphSet
+	IndentWidth          int  // Cells per level of indentation
+	Guides               bool // Draws tree(1) style lines joining items to their parents
 	ActiveItem           *TreeItem
 	ActiveLine           int         // Which line, (from 0..Height) is the cursor on?
+	OriginX              int         // Screen column of the tree's left edge, used to hit-test mouse events
+	OriginY              int         // Screen row of the tree's top edge, used to hit-test mouse events
 	Items                []*TreeItem `json:"-"`
 	initialized          bool
 	Style                lipgloss.Style
+	Styles               Styles // Replaced by WithTheme, unless set with SetStyles
+	ownStyles            bool
 	KeyMap               KeyMap
 	err                  error
 	theme                *huh.Theme
 	key                  string
 	accessible           bool
 	keymap               huh.InputKeyMap
-}
-
-func (t *Tree) Blur() tea.Cmd {
-	return nil
-}
-
-func (t *Tree) Focus() tea.Cmd {
-	return nil
-}
-
-func (t *Tree) Error() error {
-	return t.err
-}
-
-// Run runs the field individually.
-func (t *Tree) Run() error {
-	return nil
-}
-
-// Skip returns whether this input should be skipped or not.
-func (t *Tree) Skip() bool {
-	return false
-}
-
-func (t *Tree) KeyBinds() []key.Binding {
-	return nil
-}
-
-// GetValue returns the field's value.
-func (t *Tree) GetValue() any {
-	return t.ActiveItem.GetPath()
-}
-
-// GetKey returns the field's key.
-func (t *Tree) GetKey() string {
-	return t.key
-}
-
-// WithHeight sets the height of the input field.
-func (t *Tree) WithHeight(height int) huh.Field {
-	t.Height = height
-	return t
-}
-
-// WithPosition sets the position of the input field.
-func (t *Tree) WithPosition(p huh.FieldPosition) huh.Field {
-	//i.keymap.Prev.SetEnabled(!p.IsFirst())
-	//i.keymap.Next.SetEnabled(!p.IsLast())
-	//i.keymap.Submit.SetEnabled(p.IsLast())
-	return t
-}
-
-// WithTheme sets the theme on a field.
-func (t *Tree) WithTheme(theme *huh.Theme) huh.Field {
-	t.theme = theme
-	return t
-}
-
-// WithAccessible sets the accessible mode of the input field.
-func (t *Tree) WithAccessible(accessible bool) huh.Field {
-	t.accessible = accessible
-	return t
-}
-
-// WithKeyMap sets the keymap on an input field.
-func (t *Tree) WithKeyMap(k *huh.KeyMap) huh.Field {
-	t.keymap = k.Input
-	//t.textinput.KeyMap.AcceptSuggestion = i.keymap.AcceptSuggestion
-	return t
-}
-
-// WithWidth sets the width of the input field.
-func (t *Tree) WithWidth(width int) huh.Field {
-	t.Width = width
-	return t
+	focused              bool
+	title                string
+	description          string
+	validate             func([]string) error
+	value                *[]string
+	SelectionMode        SelectionMode
+	search               search
+	Rename               RenameFunc `json:"-"` // Enables inline renaming with the Rename binding
+	rename               rename
+	AllowMoves           bool     // Enables moving items with the Cut and Paste bindings
+	CanDrop              DropFunc `json:"-"` // Vetoes moves made with Paste, if set
+	cut                  []*TreeItem
+	UndoLimit            int // How many edits Undo can revert. Edits aren't recorded while it is 0
+	undoStack, redoStack []Edit
+	ShowBreadcrumb       bool // Draws the path of the active item above the tree
+	ShowStatus           bool // Draws the cursor position, child count and last error below the tree
+	lastErr              error
+	notice               string      // Shown on the status line until the next key, like lastErr
+	ShowHelp             bool        // Draws help for the bindings below the tree. The Help binding expands it
+	HelpKeys             help.KeyMap `json:"-"` // The bindings the help lists, if not the tree's own
+	help                 help.Model
+	postMu               sync.Mutex           // Guards posted, which Post is called on other goroutines to add to
+	posted               []func(*Tree)        // Funcs handed to Post, waiting for Update
+	wake                 chan struct{}        // Signalled by Post
+	waiting              atomic.Bool          // A command returned by waitForPost is running
+	folding              bool                 // The Fold prefix has been typed
+	ids                  map[string]*TreeItem // Items by ID, see FindByID
+	group                *Edit                // The step being recorded by grouped
+	replaying            bool                 // Undo or Redo is running
+	rows                 []row                // Cache of the rows on display, see visibleRows
+	rowIndex             map[*TreeItem]int
+	rowsValid            bool
+	spinner              spinner.Model
+	loads                int // How many LoadFuncs are running
+	loadID               int
+	cmds                 []tea.Cmd  // Commands to return from the next Update
+	pendingState         *TreeState // The part of an applied state waiting on loads, see ApplyState
+	SortModes            []SortMode `json:"-"` // The orders the Sort binding cycles through
+	Columns              []Column   `json:"-"` // Drawn to the right of the labels, with a header line
+	Ellipsis             string     // Ends rows cut short to fit the Width
+	ShowActiveLabel      bool       // Adds a status line with the full path of the active item
+	layout               columnLayout
+	less                 LessFunc
+	sortMode             int
+}
+
+// updateKeys enables only the bindings that can be used in the tree's current state, so
+// that the help footer doesn't advertise anything else.
+func (t *Tree) updateKeys() {
+	t.KeyMap.Check.SetEnabled(t.SelectionMode != SelectSingle)
+	t.KeyMap.Sort.SetEnabled(len(t.SortModes) > 1)
+	t.KeyMap.ScrollLeft.SetEnabled(t.Width > 0)
+	t.KeyMap.ScrollRight.SetEnabled(t.Width > 0)
+	t.KeyMap.NextMatch.SetEnabled(t.search.filtering())
+	t.KeyMap.PrevMatch.SetEnabled(t.search.filtering())
+	t.KeyMap.CancelSearch.SetEnabled(t.search.active())
+	t.KeyMap.Rename.SetEnabled(t.Rename != nil)
+	t.KeyMap.AcceptRename.SetEnabled(t.Renaming())
+	t.KeyMap.CancelRename.SetEnabled(t.Renaming())
+	t.KeyMap.Cut.SetEnabled(t.AllowMoves)
+	t.KeyMap.PasteAfter.SetEnabled(len(t.cut) > 0)
+	t.KeyMap.PasteInside.SetEnabled(len(t.cut) > 0)
+	t.KeyMap.Undo.SetEnabled(t.CanUndo())
+	t.KeyMap.Redo.SetEnabled(t.CanRedo())
+	t.KeyMap.Help.SetEnabled(t.ShowHelp)
+	if t.help.ShowAll {
+		t.KeyMap.Help.SetHelp("?", "less help")
+	} else {
+		t.KeyMap.Help.SetHelp("?", "more help")
+	}
 }
 
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		Space:    key.NewBinding(key.WithKeys(" "), key.WithHelp(" ", "space")),
+		Space:    key.NewBinding(key.WithKeys(" ", "."), key.WithHelp("space", "toggle")),
 		GoToTop:  key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "first")),
 		GoToLast: key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "last")),
 		Down:     key.NewBinding(key.WithKeys("j", "down", "ctrl+n"), key.WithHelp("j", "down")),
@@ -556,18 +434,60 @@ func DefaultKeyMap() KeyMap {
 		PageUp:   key.NewBinding(key.WithKeys("K", "pgup"), key.WithHelp("pgup", "page up")),
 		PageDown: key.NewBinding(key.WithKeys("J", "pgdown"), key.WithHelp("pgdown", "page down")),
 		Back:     key.NewBinding(key.WithKeys("h", "backspace", "left", "esc"), key.WithHelp("h", "back")),
-		Open:     key.NewBinding(key.WithKeys("l", "right", "enter"), key.WithHelp("l", "open")),
+		Open:     key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "open")),
 		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Check:    key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "check")),
+		Sort:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort")),
+
+		ScrollLeft:  key.NewBinding(key.WithKeys("H", "shift+left"), key.WithHelp("H", "scroll left")),
+		ScrollRight: key.NewBinding(key.WithKeys("L", "shift+right"), key.WithHelp("L", "scroll right")),
+
+		Search:       key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		NextMatch:    key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+		PrevMatch:    key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "prev match")),
+		AcceptSearch: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply search")),
+		CancelSearch: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel search")),
+
+		Rename:       key.NewBinding(key.WithKeys("f2", "e"), key.WithHelp("e", "rename")),
+		AcceptRename: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply name")),
+		CancelRename: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel rename")),
+
+		Cut:         key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "cut")),
+		PasteAfter:  key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "paste after")),
+		PasteInside: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "paste inside")),
+
+		Undo: key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo")),
+		Redo: key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "redo")),
+
+		Help: key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "more help")),
+
+		Fold:        key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "fold")),
+		ExpandAll:   key.NewBinding(key.WithKeys("R"), key.WithHelp("zR", "expand all")),
+		CollapseAll: key.NewBinding(key.WithKeys("M"), key.WithHelp("zM", "collapse all")),
+		OpenFold:    key.NewBinding(key.WithKeys("o"), key.WithHelp("zo", "open")),
+		CloseFold:   key.NewBinding(key.WithKeys("c"), key.WithHelp("zc", "close")),
+		OpenFoldAll: key.NewBinding(key.WithKeys("O"), key.WithHelp("zO", "open all below")),
 	}
 }
 
+// New returns an empty tree. It starts out focused, so that a host model can use it as it
+// is; a form blurs it when it is added, and focuses it when its turn comes.
 func New() tea.Model {
 	t := Tree{
-		OpenChildrenSymbol:   ChevronDown,
-		ClosedChildrenSymbol: ChevronRight,
-		KeyMap:               DefaultKeyMap(),
+		Glyphs:      NerdFontGlyphs,
+		IndentWidth: DefaultIndentWidth,
+		KeyMap:      DefaultKeyMap(),
+		SortModes:   DefaultSortModes(),
+		Ellipsis:    DefaultEllipsis,
+		Styles:      DefaultStyles(),
+		spinner:     spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		help:        help.New(),
+		validate:    func([]string) error { return nil },
+		focused:     true,
+		wake:        make(chan struct{}, 1),
 	}
 	t.setInitialValues()
+	t.updateKeys()
 	return &t
 }
 
@@ -587,17 +507,14 @@ func (t *Tree) AddChildren(i ...*TreeItem) ItemHolder {
 		return t
 	}
 	t.Lock()
-	t.Items = append(t.Items, i...)
+	insertRecorded(t, len(t.Items), i)
 	t.Unlock()
 	// After we add the items, if we didn't have an active item, let's make it the first
 	// one in the list
 	if t.ActiveItem == nil {
 		t.ActiveItem = t.Items[0]
 	}
-	for _, item := range i {
-		item.parent = t
-		item.parentTree = t
-	}
+	t.Invalidate()
 	return t
 }
 
@@ -611,48 +528,29 @@ func (t *Tree) GetPath() []string {
 	return []string{}
 }
 
+// Init applies any funcs handed to Post, and returns the command that waits for more.
 func (t *Tree) Init() tea.Cmd {
-	return nil
+	t.applyPosted()
+	return t.waitForPost()
 }
 
-// SelectPrevious - selects the previous TreeItem. This involves first getting the parent and then telling the parent to select the previous item from the current selection. If we're already at the first child, then we go to the grandparent and select the previous parent item from us, and then we descend to the most open child and activate that.rune
+// SelectPrevious moves the cursor up a row. It moves through the items that are on display,
+// which skips over anything hidden by a search filter.
 func (t *Tree) SelectPrevious() {
-	active := t.ActiveItem
-	if active != nil {
-		active.SelectPrevious()
-		return
-	}
+	t.moveActive(-1)
 }
 
 // SelectNext is like SelectPrevious, but the other way
 func (t *Tree) SelectNext() {
-	active := t.ActiveItem
-	if active != nil {
-		active.SelectNext()
-		return
-	}
+	t.moveActive(1)
 }
 
 func (t *Tree) SelectFirst() {
-	t.ActiveItem = t.Items[0]
-	t.ActiveLine = 0
-	t.ActiveItem.SelectPrevious()
-	t.Viewtop = 0
+	t.moveActive(-t.CountVisibleItems())
 }
 
 func (t *Tree) SelectLast() {
-	t.Items[len(t.Items)-1].SelectLast()
-	t.ActiveLine = t.Height - 1
-
-	t.Viewtop = t.CountVisibleItems() - t.Height
-
-	log.Println("After SelectLast:")
-	tmps, err := json.MarshalIndent(t, "", "    ")
-	if err != nil {
-		log.Println("error formatting:", err.Error())
-	} else {
-		log.Println(string(tmps))
-	}
+	t.moveActive(t.CountVisibleItems())
 }
 
 // ToggleChild will toggle the open/closed state of the current selection. This only has meaning if there
@@ -662,11 +560,116 @@ func (t *Tree) ToggleChild() {
 		t.ActiveItem.ToggleChildren()
 	}
 }
+
+// OpenChild expands the current selection if it is closed.
+func (t *Tree) OpenChild() {
+	ai := t.ActiveItem
+	if ai != nil && ai.CanHaveChildren && !ai.Open {
+		ai.ToggleChildren()
+	}
+}
+
+// Back collapses the current selection if it is open. Otherwise the cursor jumps up to the
+// selection's parent.
+func (t *Tree) Back() {
+	ai := t.ActiveItem
+	if ai == nil {
+		return
+	}
+	if ai.CanHaveChildren && ai.Open {
+		ai.ToggleChildren()
+	} else if par, ok := ai.GetParent().(*TreeItem); ok {
+		t.SetActive(par)
+	}
+	t.scrollToActive()
+}
+
+// PageUp moves the cursor up by one screenful.
+func (t *Tree) PageUp() {
+	t.moveActive(-max(t.viewHeight(), 1))
+}
+
+// PageDown moves the cursor down by one screenful.
+func (t *Tree) PageDown() {
+	t.moveActive(max(t.viewHeight(), 1))
+}
+
+// headerHeight is the number of lines drawn above the items: the theme's frame, the title
+// and description, and the search line.
+func (t *Tree) headerHeight() int {
+	height := t.frameTop()
+	if header := t.fieldHeader(); header != "" {
+		height += lipgloss.Height(header)
+	}
+	if t.ShowBreadcrumb {
+		height++
+	}
+	if t.search.active() {
+		height++
+	}
+	if len(t.Columns) > 0 {
+		height++
+	}
+	return height
+}
+
+// footerHeight is the number of lines drawn below the items: the active label, the status
+// line, the help, and the theme's frame.
+func (t *Tree) footerHeight() int {
+	base := t.fieldStyles().Base
+	height := base.GetMarginBottom() + base.GetBorderBottomSize() + base.GetPaddingBottom()
+	if t.ShowActiveLabel {
+		height++
+	}
+	if t.ShowStatus {
+		height++
+	}
+	if t.ShowHelp {
+		height += lipgloss.Height(t.helpView())
+	}
+	return height
+}
+
+// viewHeight is the number of lines left for items once the header and footer have been
+// drawn.
+func (t *Tree) viewHeight() int {
+	if t.Height > 0 {
+		return max(t.Height-t.headerHeight()-t.footerHeight(), 1)
+	}
+	return t.Height
+}
+
+// Refresh removes every item from the tree.
 func (t *Tree) Refresh() {
-	t.Items = []*TreeItem{}
+	t.keepCursor(func() {
+		replaceChildren(t, nil)
+	})
 }
 
 func (t *Tree) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previous := t.ActiveItem
+	cmd := t.update(msg)
+	if t.Renaming() && t.rename.item != t.ActiveItem {
+		// The item went away, or the cursor was moved off it by other means
+		t.CancelRename()
+	}
+	t.scrollToActive()
+	t.updateValue()
+	if t.ActiveItem != previous && t.ActiveItem != nil {
+		t.emit(ActiveChangedMsg{Tree: t, Item: t.ActiveItem, Path: t.ActiveItem.GetPath(), Previous: previous})
+	}
+	return t, t.takeCmds(cmd)
+}
+
+// takeCmds batches cmd with any commands queued while handling the current message.
+func (t *Tree) takeCmds(cmd tea.Cmd) tea.Cmd {
+	cmds := append(t.cmds, cmd)
+	t.cmds = nil
+	return tea.Batch(cmds...)
+}
+
+func (t *Tree) update(msg tea.Msg) tea.Cmd {
+	t.updateKeys()
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		// TODO: Do I take into account margin & border?
@@ -675,21 +678,114 @@ func (t *Tree) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		t.Height = msg.Height
 		t.initialized = true
 
-	// TODO: Convert these simple strings to a configurable keymap
+	case loadedMsg:
+		t.updateLoaded(msg)
+		return nil
+
+	case LoadedMsg:
+		t.updateLoadedCmd(msg)
+		return nil
+
+	case postedMsg:
+		return t.updatePosted(msg)
+
+	case streamedMsg:
+		return t.updateStreamed(msg)
+
+	case spinner.TickMsg:
+		return t.updateSpinner(msg)
+
+	case tea.MouseMsg:
+		t.updateMouse(msg)
+		return nil
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "?":
-			log.Println("info")
-		case "up", "k":
+		t.lastErr, t.notice = nil, ""
+		if t.search.typing {
+			return t.updateSearch(msg)
+		}
+		if t.Renaming() {
+			return t.updateRename(msg)
+		}
+		if t.folding {
+			t.folding = false
+			t.updateFold(msg)
+			return nil
+		}
+		if cmd, ok := t.updateField(msg); ok {
+			return cmd
+		}
+		switch {
+		case key.Matches(msg, t.KeyMap.Search):
+			return t.StartSearch()
+		case key.Matches(msg, t.KeyMap.Rename):
+			return t.StartRename()
+		case key.Matches(msg, t.KeyMap.Fold):
+			t.folding = true
+			return nil
+		case key.Matches(msg, t.KeyMap.Cut):
+			t.Cut()
+			return nil
+		case key.Matches(msg, t.KeyMap.PasteAfter):
+			t.reportError(t.Paste(false))
+			return nil
+		case key.Matches(msg, t.KeyMap.PasteInside):
+			t.reportError(t.Paste(true))
+			return nil
+		case key.Matches(msg, t.KeyMap.Undo):
+			t.Undo()
+			return nil
+		case key.Matches(msg, t.KeyMap.Redo):
+			t.Redo()
+			return nil
+		case key.Matches(msg, t.KeyMap.CancelSearch):
+			t.CancelSearch()
+			return nil
+		case key.Matches(msg, t.KeyMap.NextMatch):
+			t.NextMatch()
+			return nil
+		case key.Matches(msg, t.KeyMap.PrevMatch):
+			t.PrevMatch()
+			return nil
+		case key.Matches(msg, t.KeyMap.Help):
+			t.ToggleHelp()
+			return nil
+		case key.Matches(msg, t.KeyMap.Up):
 			t.SelectPrevious()
-		case "down", "j":
+		case key.Matches(msg, t.KeyMap.Down):
 			t.SelectNext()
-		case " ", ".":
+		case key.Matches(msg, t.KeyMap.PageUp):
+			t.PageUp()
+		case key.Matches(msg, t.KeyMap.PageDown):
+			t.PageDown()
+		case key.Matches(msg, t.KeyMap.Space):
 			t.ToggleChild()
-			return t, nil
-		case "g": // go to top
+			return nil
+		case key.Matches(msg, t.KeyMap.Open):
+			t.OpenChild()
+			return nil
+		case key.Matches(msg, t.KeyMap.Back):
+			t.Back()
+			return nil
+		case key.Matches(msg, t.KeyMap.Check):
+			if t.ActiveItem != nil {
+				t.ToggleChecked(t.ActiveItem)
+			}
+			return nil
+		case key.Matches(msg, t.KeyMap.ScrollLeft):
+			t.ScrollLeft(hscrollStep)
+			return nil
+		case key.Matches(msg, t.KeyMap.ScrollRight):
+			t.ScrollRight(hscrollStep)
+			return nil
+		case key.Matches(msg, t.KeyMap.Sort):
+			mode := t.CycleSort()
+			t.notice = "sorted by " + mode
+			t.emit(SortedMsg{Tree: t, Mode: mode})
+			return nil
+		case key.Matches(msg, t.KeyMap.GoToTop):
 			t.SelectFirst()
-		case "G": // Go to bottom
+		case key.Matches(msg, t.KeyMap.GoToLast):
 			t.SelectLast()
 		}
 	}
@@ -700,59 +796,25 @@ func (t *Tree) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		i, cmd = t.ActiveItem.Update(msg)
 		t.ActiveItem = i.(*TreeItem)
 	}
-	return t, cmd
+	return cmd
 }
 
+// SetActive moves the cursor to ti, scrolling it into view if it is on display. When this
+// happens while handling a message in Update, an ActiveChangedMsg is returned from it.
 func (t *Tree) SetActive(ti *TreeItem) {
-	if t.ActiveItem.exiting != nil {
-		t.ActiveItem.exiting(t.ActiveItem)
-	}
 	t.ActiveItem = ti
-	if t.ActiveItem.entering != nil {
-		t.ActiveItem.entering(t.ActiveItem)
-	}
+	t.scrollToActive()
 }
 
 func (t *Tree) CountVisibleItems() int {
-	total := 0
-	for _, i := range t.Items {
-		total += i.CountItemAndChildren()
-	}
-	return total
+	return len(t.visibleRows())
 }
 
 // ScrollDown moves the "display" area down the virtual list. This actually looks like scrolling up ((the items move up the screen) Not sure if this is counterintuitive or not
 func (t *Tree) ScrollDown(n int) {
-	t.Viewtop += n
+	t.scrollBy(n)
 }
 
 func (t *Tree) ScrollUp(n int) {
-	t.Viewtop -= n
-}
-
-func (t *Tree) View() string {
-	if !t.initialized {
-		return ""
-	}
-	var views []string
-
-	// Iterate through the children, calling View() on each of them.
-	curline := -t.Viewtop
-	bottom := t.Height
-	var v string
-	for _, item := range t.Items {
-		item.indent = 0
-		curline, v = item.ViewScrolled(t.Viewtop, curline, bottom)
-		if curline > bottom {
-			break
-		}
-		if v != "" {
-			views = append(views, v)
-		}
-	}
-
-	s := lipgloss.JoinVertical(
-		lipgloss.Left, views...,
-	)
-	return s
+	t.scrollBy(-n)
 }
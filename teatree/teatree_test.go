@@ -1,9 +1,12 @@
 package teatree
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -68,6 +71,112 @@ func TestSymbols(t *testing.T) {
 	fmt.Println(redstyle.Render(Organization), "Organization")
 }
 
+// newTestTree builds a small tree:
+//
+//	a
+//	  a1
+//	  a2
+//	b
+//	  b1
+//	c
+func newTestTree(height int) *Tree {
+	tr := New().(*Tree)
+	tr.Height = height
+	a := NewItem("a", true, nil, nil, nil, nil, nil, nil, nil)
+	b := NewItem("b", true, nil, nil, nil, nil, nil, nil, nil)
+	c := NewItem("c", false, nil, nil, nil, nil, nil, nil, nil)
+	tr.AddChildren(a, b, c)
+	a.AddChildren(
+		NewItem("a1", false, nil, nil, nil, nil, nil, nil, nil),
+		NewItem("a2", false, nil, nil, nil, nil, nil, nil, nil),
+	)
+	b.AddChildren(NewItem("b1", false, nil, nil, nil, nil, nil, nil, nil))
+	return tr
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "ctrl+f":
+		return tea.KeyMsg{Type: tea.KeyCtrlF}
+	case "ctrl+b":
+		return tea.KeyMsg{Type: tea.KeyCtrlB}
+	case "ctrl+r":
+		return tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "pgdown":
+		return tea.KeyMsg{Type: tea.KeyPgDown}
+	case "pgup":
+		return tea.KeyMsg{Type: tea.KeyPgUp}
+	case "shift+tab":
+		return tea.KeyMsg{Type: tea.KeyShiftTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestKeyMap(t *testing.T) {
+	tr := newTestTree(10)
+	tr.KeyMap.Down = key.NewBinding(key.WithKeys("ctrl+f"))
+	tr.KeyMap.Up = key.NewBinding(key.WithKeys("ctrl+b"))
+
+	tr.Update(keyMsg("j"))
+	if tr.ActiveItem.Name != "a" {
+		t.Fatalf("unbound key moved the cursor to %q", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("ctrl+f"))
+	if tr.ActiveItem.Name != "b" {
+		t.Fatalf("remapped down: got %q, want b", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("ctrl+b"))
+	if tr.ActiveItem.Name != "a" {
+		t.Fatalf("remapped up: got %q, want a", tr.ActiveItem.Name)
+	}
+
+	// Open expands, Back from a child jumps to the parent, Back again collapses.
+	tr.Update(keyMsg("l"))
+	if !tr.ActiveItem.Open {
+		t.Fatal("open did not expand the item")
+	}
+	tr.Update(keyMsg("ctrl+f"))
+	if tr.ActiveItem.Name != "a1" {
+		t.Fatalf("got %q, want a1", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("h"))
+	if tr.ActiveItem.Name != "a" || tr.ActiveLine != 0 {
+		t.Fatalf("back: got %q on line %d, want a on line 0", tr.ActiveItem.Name, tr.ActiveLine)
+	}
+	tr.Update(keyMsg("h"))
+	if tr.ActiveItem.Open {
+		t.Fatal("back did not collapse the item")
+	}
+
+	if len(tr.KeyBinds()) == 0 {
+		t.Fatal("KeyBinds returned no bindings")
+	}
+}
+
+func TestPaging(t *testing.T) {
+	tr := newTestTree(2)
+	tr.Items[0].OpenChildren()
+	tr.Items[1].OpenChildren()
+
+	tr.Update(keyMsg("pgdown"))
+	if tr.ActiveItem.Name != "a2" || tr.ActiveLine != 1 || tr.Viewtop != 1 {
+		t.Fatalf("page down: got %q line %d top %d", tr.ActiveItem.Name, tr.ActiveLine, tr.Viewtop)
+	}
+	tr.Update(keyMsg("pgdown"))
+	tr.Update(keyMsg("pgdown"))
+	if tr.ActiveItem.Name != "c" {
+		t.Fatalf("page down past the end: got %q, want c", tr.ActiveItem.Name)
+	}
+	tr.Update(keyMsg("pgup"))
+	if tr.ActiveItem.Name != "b" || tr.ActiveLine != 0 || tr.Viewtop != 3 {
+		t.Fatalf("page up: got %q line %d top %d", tr.ActiveItem.Name, tr.ActiveLine, tr.Viewtop)
+	}
+}
+
 /*
 func TestTree(t *testing.T) {
 	m := New()
@@ -120,3 +229,16 @@ So with a "topscroll" = -7, rendering starts with Item 1. It is open, as is its
 Renders Item 1. The current render line is -7 so we don't actually render, but we do a "lipglosss.JoinVertical"
 
 `
+
+// The filebrowser logs its model as JSON, so every feature has to leave the tree marshalable.
+func TestTreeMarshals(t *testing.T) {
+	tr := newTestTree(10)
+	tr.Rename = func(*TreeItem, string) error { return nil }
+	tr.AllowMoves = true
+	tr.CanDrop = func(item, parent *TreeItem) bool { return true }
+	tr.Columns = []Column{{Title: "size", Width: 4, Cell: func(*TreeItem) string { return "" }}}
+	tr.Update(keyMsg("s"))
+	if _, err := json.Marshal(tr); err != nil {
+		t.Fatal(err)
+	}
+}
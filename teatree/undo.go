@@ -0,0 +1,132 @@
+package teatree
+
+import "fmt"
+
+// DefaultUndoLimit is a reasonable value for Tree.UndoLimit.
+const DefaultUndoLimit = 100
+
+// Edit is one step in the undo history: a change to the structure of the tree made through
+// its methods, such as AddChildren, RemoveItem, MoveItem, ReplaceChildren, Paste or an inline
+// rename.
+type Edit struct {
+	Description string      // Says what was done, such as "rename a to b"
+	Items       []*TreeItem // The items that were added, removed, moved or renamed
+	undo, redo  []func()
+}
+
+// record adds a step to the undo history, or to the group being recorded, and throws away
+// anything that was undone before it. It does nothing if t is nil, recording is off, or the
+// change is itself an undo or redo.
+func (t *Tree) record(desc string, items []*TreeItem, undo, redo func()) {
+	if t == nil || t.UndoLimit <= 0 || t.replaying {
+		return
+	}
+	if g := t.group; g != nil {
+		g.Items = append(g.Items, items...)
+		g.undo = append(g.undo, undo)
+		g.redo = append(g.redo, redo)
+		return
+	}
+	t.push(Edit{Description: desc, Items: items, undo: []func(){undo}, redo: []func(){redo}})
+}
+
+// grouped runs fn, recording every change it makes as a single step.
+func (t *Tree) grouped(desc string, fn func()) {
+	if t.group != nil {
+		fn()
+		return
+	}
+	t.group = &Edit{Description: desc}
+	fn()
+	g := t.group
+	t.group = nil
+	if len(g.undo) > 0 {
+		t.push(*g)
+	}
+}
+
+func (t *Tree) push(e Edit) {
+	t.undoStack = append(t.undoStack, e)
+	if over := len(t.undoStack) - t.UndoLimit; over > 0 {
+		t.undoStack = t.undoStack[over:]
+	}
+	t.redoStack = nil
+}
+
+// CanUndo reports whether there is an edit to undo.
+func (t *Tree) CanUndo() bool {
+	return len(t.undoStack) > 0
+}
+
+// CanRedo reports whether there is an undone edit to redo.
+func (t *Tree) CanRedo() bool {
+	return len(t.redoStack) > 0
+}
+
+// ClearUndo forgets the undo history.
+func (t *Tree) ClearUndo() {
+	t.undoStack = nil
+	t.redoStack = nil
+}
+
+// Undo reverts the last edit and sends an UndoneMsg describing it, so that the host model
+// can revert its own changes to match. It reports whether there was anything to undo.
+func (t *Tree) Undo() bool {
+	if !t.CanUndo() {
+		return false
+	}
+	e := t.undoStack[len(t.undoStack)-1]
+	t.undoStack = t.undoStack[:len(t.undoStack)-1]
+	t.replay(e, func() {
+		for x := len(e.undo) - 1; x >= 0; x-- {
+			e.undo[x]()
+		}
+	})
+	t.redoStack = append(t.redoStack, e)
+	t.emit(UndoneMsg{Tree: t, Edit: e})
+	return true
+}
+
+// Redo makes the last undone edit again and sends a RedoneMsg describing it. It reports
+// whether there was anything to redo.
+func (t *Tree) Redo() bool {
+	if !t.CanRedo() {
+		return false
+	}
+	e := t.redoStack[len(t.redoStack)-1]
+	t.redoStack = t.redoStack[:len(t.redoStack)-1]
+	t.replay(e, func() {
+		for _, redo := range e.redo {
+			redo()
+		}
+	})
+	t.undoStack = append(t.undoStack, e)
+	t.emit(RedoneMsg{Tree: t, Edit: e})
+	return true
+}
+
+// replay runs the undo or redo funcs of e without recording them, and then puts the cursor
+// on the first of its items that is still in the tree.
+func (t *Tree) replay(e Edit, fn func()) {
+	t.CancelRename()
+	t.cut = nil
+	t.replaying = true
+	t.keepCursor(fn)
+	t.replaying = false
+	for _, ti := range e.Items {
+		if ti.parentTree == t {
+			t.reveal(ti)
+			t.SetActive(ti)
+			break
+		}
+	}
+	t.scrollToActive()
+}
+
+// describe names the items an edit was made to, for its Description.
+func describe(verb string, items []*TreeItem) string {
+	if len(items) == 1 {
+		return verb + " " + items[0].Name
+	}
+	return fmt.Sprintf("%s %d items", verb, len(items))
+}
@@ -0,0 +1,93 @@
+package teatree
+
+import (
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	tr := newTestTree(10)
+	a, b := tr.Items[0], tr.Items[1]
+	tr.RemoveItem(b)
+	if tr.CanUndo() {
+		t.Fatal("edits should not be recorded while UndoLimit is 0")
+	}
+
+	tr = newTestTree(10)
+	tr.UndoLimit = DefaultUndoLimit
+	a, b = tr.Items[0], tr.Items[1]
+	tr.RemoveItem(b)
+	tr.MoveItem(a, tr, 1)
+	tr.AddChildren(NewItem("d", false, nil, nil, nil, nil, nil, nil, nil))
+	if got := names(tr.Items); got != "c,a,d" {
+		t.Fatalf("setup: got %s", got)
+	}
+
+	_, cmd := tr.Update(keyMsg("u"))
+	var undone *UndoneMsg
+	for _, msg := range run(cmd) {
+		if m, ok := msg.(UndoneMsg); ok {
+			undone = &m
+		}
+	}
+	if undone == nil || undone.Edit.Description != "add d" {
+		t.Fatalf("expected UndoneMsg for add d, got %#v", undone)
+	}
+	tr.Update(keyMsg("u"))
+	tr.Update(keyMsg("u"))
+	if got := names(tr.Items); got != "a,b,c" {
+		t.Fatalf("after undoing everything: got %s", got)
+	}
+	if b.GetParent() != ItemHolder(tr) || names(b.Children) != "b1" {
+		t.Fatal("b was not put back whole")
+	}
+	if tr.Undo() {
+		t.Fatal("nothing should be left to undo")
+	}
+
+	tr.Update(keyMsg("ctrl+r"))
+	if got := names(tr.Items); got != "a,c" {
+		t.Fatalf("after redo: got %s", got)
+	}
+	// A new edit drops what was undone
+	tr.RemoveItem(a)
+	if tr.CanRedo() {
+		t.Fatal("redo history should be cleared by a new edit")
+	}
+}
+
+func TestUndoGrouped(t *testing.T) {
+	tr := newTestTree(10)
+	tr.UndoLimit = DefaultUndoLimit
+	tr.AllowMoves = true
+	tr.Rename = func(*TreeItem, string) error { return nil }
+	tr.SelectionMode = SelectMulti
+	a, b, c := tr.Items[0], tr.Items[1], tr.Items[2]
+	tr.SetChecked(a.Children[0], true)
+	tr.SetChecked(b.Children[0], true)
+	tr.Cut()
+	tr.SetActive(c)
+	tr.Paste(true)
+
+	tr.SetActive(c)
+	tr.StartRename()
+	tr.rename.input.SetValue("z")
+	tr.CommitRename()
+
+	if !tr.Undo() || c.Name != "c" {
+		t.Fatalf("rename not undone: %q", c.Name)
+	}
+	if !tr.Undo() {
+		t.Fatal("paste not recorded")
+	}
+	if got := names(a.Children) + ";" + names(b.Children) + ";" + names(c.Children); got != "a1,a2;b1;" {
+		t.Fatalf("the paste should be undone in one step: got %s", got)
+	}
+
+	tr.UndoLimit = 1
+	tr.RemoveItem(a)
+	tr.RemoveItem(b)
+	tr.Undo()
+	if tr.Undo() {
+		t.Fatal("history should be capped at UndoLimit")
+	}
+}